@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+// runCommand runs a command line operation against the module data instead of starting the service
+func runCommand(c serviceConfig, args []string) error {
+	err := directory.New(c.FreeSWITCH.ModuleDataDirectory, c.HTTP.TemplatesDir)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "directory-set-password":
+		return setPasswordCommand(args[1:])
+	case "directory-rename-domain":
+		return renameDomainCommand(args[1:])
+	case "directory-import":
+		return importCommand(args[1:])
+	case "directory-effective":
+		return effectiveCommand(args[1:])
+	case "moduledata-split":
+		return splitCommand(c.FreeSWITCH.ModuleDataDirectory, args[1:])
+	}
+	return fmt.Errorf("unknown command [%s]", args[0])
+}
+
+// setPasswordCommand stores the a1-hash of the password read from stdin, so it never shows up in
+// the shell history or process list
+func setPasswordCommand(args []string) error {
+	fs := flag.NewFlagSet("directory-set-password", flag.ContinueOnError)
+	hostname := fs.String("hostname", "", "FreeSWITCH hostname")
+	domain := fs.String("domain", "", "directory domain")
+	user := fs.String("user", "", "directory user id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *hostname == "" || *domain == "" || *user == "" {
+		return errors.New("hostname, domain and user are required")
+	}
+	fmt.Fprintf(os.Stderr, "password for [%s@%s]: ", *user, *domain)
+	s := bufio.NewScanner(os.Stdin)
+	if !s.Scan() {
+		return errors.New("no password given")
+	}
+	return directory.SetPassword(*hostname, *domain, *user, strings.TrimRight(s.Text(), "\r"))
+}
+
+// renameDomainCommand renames a domain, reading `user,password` lines from stdin for the users whose
+// a1-hash should be recomputed
+func renameDomainCommand(args []string) error {
+	fs := flag.NewFlagSet("directory-rename-domain", flag.ContinueOnError)
+	hostname := fs.String("hostname", "", "FreeSWITCH hostname")
+	domain := fs.String("domain", "", "directory domain")
+	newDomain := fs.String("new-domain", "", "new directory domain name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *hostname == "" || *domain == "" || *newDomain == "" {
+		return errors.New("hostname, domain and new-domain are required")
+	}
+	passwords := map[string]string{}
+	s := bufio.NewScanner(os.Stdin)
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" {
+			continue
+		}
+		p := strings.SplitN(l, ",", 2)
+		if len(p) != 2 {
+			return fmt.Errorf("expected user,password [%s]", p[0])
+		}
+		passwords[p[0]] = p[1]
+	}
+	stale, err := directory.RenameDomain(*hostname, *domain, *newDomain, passwords)
+	if err != nil {
+		return err
+	}
+	for _, u := range stale {
+		fmt.Printf("password needs to be set again [%s@%s]\n", u, *newDomain)
+	}
+	return nil
+}
+
+// importCommand upserts directory users from a csv file, or stdin when no file is given
+func importCommand(args []string) error {
+	fs := flag.NewFlagSet("directory-import", flag.ContinueOnError)
+	hostname := fs.String("hostname", "", "FreeSWITCH hostname")
+	file := fs.String("file", "", "csv file to import, defaults to stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *hostname == "" {
+		return errors.New("hostname is required")
+	}
+	r := os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	res, err := directory.Import(*hostname, r)
+	if err != nil {
+		return err
+	}
+	for _, e := range res.Errors {
+		fmt.Printf("row %d: %s\n", e.Row, e.Error)
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("nothing imported, [%d] invalid rows", len(res.Errors))
+	}
+	fmt.Printf("created [%d] updated [%d] users\n", res.Created, res.Updated)
+	return nil
+}
+
+// effectiveCommand prints the params and variables a user ends up with and where each was set
+func effectiveCommand(args []string) error {
+	fs := flag.NewFlagSet("directory-effective", flag.ContinueOnError)
+	hostname := fs.String("hostname", "", "FreeSWITCH hostname")
+	domain := fs.String("domain", "", "directory domain")
+	user := fs.String("user", "", "directory user id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	e, err := directory.Effective(*hostname, *domain, *user)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("user [%s@%s] groups [%s]\n", e.ID, e.Domain, strings.Join(e.Groups, ","))
+	for _, p := range e.Params {
+		fmt.Printf("param    %s=%s (%s)\n", p.Name, p.Value, p.Source)
+	}
+	for _, v := range e.Variables {
+		fmt.Printf("variable %s=%s (%s)\n", v.Name, v.Value, v.Source)
+	}
+	return nil
+}
+
+// splitCommand moves every host out of the shared module data files into hosts/<hostname>/
+func splitCommand(dir string, args []string) error {
+	fs := flag.NewFlagSet("moduledata-split", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only print the files that would be written")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	moves, err := moduledata.Split(dir, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, m := range moves {
+		fmt.Printf("%s [%s] -> %s\n", m.From, m.Hostname, m.To)
+	}
+	if *dryRun {
+		fmt.Printf("[%d] hosts would be moved\n", len(moves))
+		return nil
+	}
+	fmt.Printf("moved [%d] hosts\n", len(moves))
+	return nil
+}
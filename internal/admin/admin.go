@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"crypto/subtle"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/secret"
+)
+
+var (
+	username string
+	password string
+)
+
+// New sets the basic auth credentials of the admin API. The admin API changes module data, so it
+// stays disabled until a user is configured
+func New(user string, passwordSecret string) error {
+	username, password = "", ""
+	if user == "" {
+		rlog.Info("admin api disabled")
+		return nil
+	}
+	p, err := secret.Get(passwordSecret)
+	if err != nil {
+		return err
+	}
+	username = user
+	password = p
+	rlog.Infof("set admin api user [%s]", username)
+	return nil
+}
+
+// Enabled reports whether admin credentials have been set up
+func Enabled() bool {
+	return username != ""
+}
+
+// Authorized checks basic auth credentials against the admin user
+func Authorized(user string, pass string, ok bool) bool {
+	if !Enabled() || !ok {
+		return false
+	}
+	u := subtle.ConstantTimeCompare([]byte(user), []byte(username))
+	p := subtle.ConstantTimeCompare([]byte(pass), []byte(password))
+	return u&p == 1
+}
@@ -0,0 +1,309 @@
+package cdr
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/secret"
+)
+
+const (
+	filePrefix = "cdr-"
+	fileSuffix = ".jsonl"
+	fileDate   = "2006-01-02"
+
+	defaultLimit = 100
+	maxLimit     = 10000
+)
+
+var (
+	storeDirectory string
+	username       string
+	password       string
+
+	// serializes appends so records written by concurrent posts never interleave, and guards the index
+	mu sync.RWMutex
+
+	idx = newIndex()
+
+	// days of records kept, today included, 0 keeps every record
+	retentionDays int
+	// the UTC day the retention was last applied, a new day removes the oldest day file
+	prunedDay string
+)
+
+// Record is a stored CDR. The core fields are extracted when the CDR is received so queries never
+// have to parse the original document, which is kept as-is in Raw
+type Record struct {
+	UUID              string    `json:"uuid"`
+	Hostname          string    `json:"hostname"`
+	CallerIDName      string    `json:"caller_id_name"`
+	CallerIDNumber    string    `json:"caller_id_number"`
+	DestinationNumber string    `json:"destination_number"`
+	Context           string    `json:"context"`
+	Direction         string    `json:"direction"`
+	HangupCause       string    `json:"hangup_cause"`
+	StartTime         time.Time `json:"start_time"`
+	AnswerTime        time.Time `json:"answer_time"`
+	EndTime           time.Time `json:"end_time"`
+	Duration          int       `json:"duration"`
+	Billsec           int       `json:"billsec"`
+	Format            string    `json:"format"`
+	Raw               string    `json:"raw"`
+}
+
+// Query filters stored records, empty fields match everything
+type Query struct {
+	Hostname    string
+	Caller      string
+	Destination string
+	From        time.Time
+	To          time.Time
+	Limit       int
+}
+
+// New sets the directory CDRs are stored in and the optional basic auth credentials. An empty
+// directory disables CDR storage. The index of the stored records is built here, once
+func New(d string, user string, passwordSecret string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	storeDirectory = ""
+	idx = newIndex()
+	if d == "" {
+		rlog.Info("cdr storage disabled")
+		return nil
+	}
+	if err := os.MkdirAll(d, 0750); err != nil {
+		rlog.Errorf("could not create cdr directory [%s]", err.Error())
+		return err
+	}
+	username, password = "", ""
+	if user != "" {
+		p, err := secret.Get(passwordSecret)
+		if err != nil {
+			return err
+		}
+		username = user
+		password = p
+		rlog.Infof("set cdr basic auth user [%s]", username)
+	}
+	if err := prune(d, time.Now().UTC()); err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(d, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		if err = idx.load(f); err != nil {
+			rlog.Errorf("could not index cdr file [%s]", err.Error())
+			return err
+		}
+	}
+	storeDirectory = d
+	rlog.Infof("set cdr directory [%s] with [%d] records", storeDirectory, len(idx.all))
+	return nil
+}
+
+// Retention keeps the records of the last `days` UTC days, today included. Older day files are
+// removed together with their records now and whenever a new day starts. 0 keeps every record
+func Retention(days int) error {
+	if days < 0 {
+		return fmt.Errorf("cdr retention can not be negative [%d]", days)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	retentionDays = days
+	if retentionDays > 0 {
+		rlog.Infof("set cdr retention [%d] days", retentionDays)
+	}
+	if storeDirectory == "" {
+		return nil
+	}
+	return prune(storeDirectory, time.Now().UTC())
+}
+
+// Enabled reports whether CDR storage has been set up
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return storeDirectory != ""
+}
+
+// Authorized checks basic auth credentials, every request is authorized when no user is configured
+func Authorized(user string, pass string, ok bool) bool {
+	mu.RLock()
+	wantUser, wantPass := username, password
+	mu.RUnlock()
+	if wantUser == "" {
+		return true
+	}
+	if !ok {
+		return false
+	}
+	u := subtle.ConstantTimeCompare([]byte(user), []byte(wantUser))
+	p := subtle.ConstantTimeCompare([]byte(pass), []byte(wantPass))
+	return u&p == 1
+}
+
+// Store appends the record to the file for the UTC day the call started on
+func Store(r Record) error {
+	if r.StartTime.IsZero() {
+		r.StartTime = time.Now().UTC()
+	}
+	d, err := json.Marshal(r)
+	if err != nil {
+		rlog.Errorf("could not marshal cdr [%s]", err.Error())
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if storeDirectory == "" {
+		return errors.New("cdr storage disabled")
+	}
+	now := time.Now().UTC()
+	if now.Format(fileDate) != prunedDay {
+		if err = prune(storeDirectory, now); err != nil {
+			return err
+		}
+	}
+	if retentionDays > 0 && r.StartTime.Before(oldestDay(now)) {
+		rlog.Infof("cdr older than the retention is not stored [%s] [%s]", r.UUID, r.StartTime.Format(time.RFC3339))
+		return nil
+	}
+	name := dayFile(r.StartTime)
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		rlog.Errorf("could not open cdr file [%s]", err.Error())
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(d, '\n')); err != nil {
+		rlog.Errorf("could not write cdr [%s]", err.Error())
+		return err
+	}
+	idx.add(newRef(r, name, fi.Size(), len(d)))
+	return nil
+}
+
+// Find returns the records matching the query, newest first. Records are picked through the index,
+// only the ones returned are read from the day files
+func Find(q Query) ([]Record, error) {
+	if !Enabled() {
+		return nil, errors.New("cdr storage disabled")
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+	mu.RLock()
+	refs := idx.find(q)
+	mu.RUnlock()
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].start.After(refs[j].start)
+	})
+	if len(refs) > q.Limit {
+		refs = refs[:q.Limit]
+	}
+	res := make([]Record, 0, len(refs))
+	files := map[string]*os.File{}
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, r := range refs {
+		f, ok := files[r.file]
+		if !ok {
+			var err error
+			if f, err = os.Open(r.file); err != nil {
+				rlog.Errorf("could not open cdr file [%s]", err.Error())
+				return nil, err
+			}
+			files[r.file] = f
+		}
+		c, err := r.read(f)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+func (q Query) match(r *ref) bool {
+	if q.Hostname != "" && q.Hostname != r.hostname {
+		return false
+	}
+	if q.Caller != "" && q.Caller != r.callerIDNumber {
+		return false
+	}
+	if q.Destination != "" && q.Destination != r.destinationNumber {
+		return false
+	}
+	if !q.From.IsZero() && r.start.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && r.start.After(q.To) {
+		return false
+	}
+	return true
+}
+
+// prune removes the day files in `d` older than the retention and drops their records from the
+// index. mu has to be held
+func prune(d string, now time.Time) error {
+	prunedDay = now.Format(fileDate)
+	if retentionDays == 0 {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(d, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return err
+	}
+	oldest := oldestDay(now)
+	removed := map[string]bool{}
+	for _, f := range files {
+		day, err := time.Parse(fileDate, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(f), filePrefix), fileSuffix))
+		if err != nil || !day.Before(oldest) {
+			continue
+		}
+		if err = os.Remove(f); err != nil {
+			rlog.Errorf("could not remove cdr file [%s]", err.Error())
+			return err
+		}
+		removed[f] = true
+		rlog.Infof("removed cdr file past the retention [%s]", f)
+	}
+	if len(removed) > 0 {
+		idx.drop(removed)
+	}
+	return nil
+}
+
+// oldestDay returns the start of the oldest UTC day kept by the retention
+func oldestDay(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-retentionDays)
+}
+
+func dayFile(t time.Time) string {
+	return filepath.Join(storeDirectory, filePrefix+t.UTC().Format(fileDate)+fileSuffix)
+}
@@ -0,0 +1,133 @@
+package cdr
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/romana/rlog"
+)
+
+// ref locates a stored record in its day file together with the core fields queries filter on
+type ref struct {
+	file              string
+	offset            int64
+	length            int
+	hostname          string
+	callerIDNumber    string
+	destinationNumber string
+	start             time.Time
+}
+
+// index of the stored records by their core fields. Each filter maps a value to the records having
+// it, a query starts from the shortest list of the filters it sets
+type index struct {
+	all           []*ref
+	byHostname    map[string][]*ref
+	byCaller      map[string][]*ref
+	byDestination map[string][]*ref
+}
+
+func newIndex() *index {
+	return &index{
+		byHostname:    map[string][]*ref{},
+		byCaller:      map[string][]*ref{},
+		byDestination: map[string][]*ref{},
+	}
+}
+
+func newRef(r Record, file string, offset int64, length int) *ref {
+	return &ref{
+		file:              file,
+		offset:            offset,
+		length:            length,
+		hostname:          r.Hostname,
+		callerIDNumber:    r.CallerIDNumber,
+		destinationNumber: r.DestinationNumber,
+		start:             r.StartTime,
+	}
+}
+
+func (i *index) add(r *ref) {
+	i.all = append(i.all, r)
+	i.byHostname[r.hostname] = append(i.byHostname[r.hostname], r)
+	i.byCaller[r.callerIDNumber] = append(i.byCaller[r.callerIDNumber], r)
+	i.byDestination[r.destinationNumber] = append(i.byDestination[r.destinationNumber], r)
+}
+
+// drop removes the records of `files` from the index
+func (i *index) drop(files map[string]bool) {
+	all := i.all
+	*i = *newIndex()
+	for _, r := range all {
+		if !files[r.file] {
+			i.add(r)
+		}
+	}
+}
+
+// load indexes the records of a day file
+func (i *index) load(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var offset int64
+	rd := bufio.NewReaderSize(f, 64*1024)
+	for {
+		l, err := rd.ReadBytes('\n')
+		if len(l) > 0 && l[len(l)-1] == '\n' {
+			r := Record{}
+			if err := json.Unmarshal(l, &r); err != nil {
+				rlog.Errorf("skipping unreadable cdr in [%s] [%s]", name, err.Error())
+			} else {
+				i.add(newRef(r, name, offset, len(l)-1))
+			}
+		}
+		offset += int64(len(l))
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// find returns the records matching the query
+func (i *index) find(q Query) []*ref {
+	candidates := i.all
+	for _, l := range []struct {
+		value string
+		refs  map[string][]*ref
+	}{
+		{q.Hostname, i.byHostname},
+		{q.Caller, i.byCaller},
+		{q.Destination, i.byDestination},
+	} {
+		if l.value != "" && len(l.refs[l.value]) < len(candidates) {
+			candidates = l.refs[l.value]
+		}
+	}
+	res := []*ref{}
+	for _, r := range candidates {
+		if q.match(r) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// read loads the record from its day file
+func (r *ref) read(f *os.File) (Record, error) {
+	c := Record{}
+	d := make([]byte, r.length)
+	if _, err := f.ReadAt(d, r.offset); err != nil {
+		return c, err
+	}
+	err := json.Unmarshal(d, &c)
+	return c, err
+}
@@ -0,0 +1,154 @@
+package cdr
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type xmlValue struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type callerProfile struct {
+	CallerIDName      string `xml:"caller_id_name" json:"caller_id_name"`
+	CallerIDNumber    string `xml:"caller_id_number" json:"caller_id_number"`
+	DestinationNumber string `xml:"destination_number" json:"destination_number"`
+	Context           string `xml:"context" json:"context"`
+}
+
+type xmlCDR struct {
+	CoreUUID    string `xml:"core-uuid,attr"`
+	Switchname  string `xml:"switchname,attr"`
+	ChannelData struct {
+		Direction string `xml:"direction"`
+	} `xml:"channel_data"`
+	Variables struct {
+		Values []xmlValue `xml:",any"`
+	} `xml:"variables"`
+	Callflow []struct {
+		CallerProfile callerProfile `xml:"caller_profile"`
+	} `xml:"callflow"`
+}
+
+type jsonCDR struct {
+	CoreUUID    string `json:"core-uuid"`
+	Switchname  string `json:"switchname"`
+	ChannelData struct {
+		Direction string `json:"direction"`
+	} `json:"channel_data"`
+	Variables map[string]interface{} `json:"variables"`
+	// an array of callflows in current releases, a single object in older ones
+	Callflow json.RawMessage `json:"callflow"`
+}
+
+type jsonCallflow struct {
+	CallerProfile callerProfile `json:"caller_profile"`
+}
+
+// ParseXML extracts the core fields of a mod_xml_cdr document
+func ParseXML(b []byte) (Record, error) {
+	c := xmlCDR{}
+	if err := xml.Unmarshal(b, &c); err != nil {
+		return Record{}, err
+	}
+	v := map[string]string{}
+	for _, x := range c.Variables.Values {
+		v[x.XMLName.Local] = unescape(x.Value)
+	}
+	cp := callerProfile{}
+	if len(c.Callflow) > 0 {
+		cp = c.Callflow[0].CallerProfile
+	}
+	r := record(v, cp)
+	r.Hostname = c.Switchname
+	r.Direction = c.ChannelData.Direction
+	r.Format = "xml"
+	r.Raw = string(b)
+	if r.UUID == "" {
+		return Record{}, errors.New("cdr has no uuid")
+	}
+	return r, nil
+}
+
+// ParseJSON extracts the core fields of a mod_json_cdr document
+func ParseJSON(b []byte) (Record, error) {
+	c := jsonCDR{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Record{}, err
+	}
+	v := map[string]string{}
+	for k, x := range c.Variables {
+		switch t := x.(type) {
+		case string:
+			v[k] = unescape(t)
+		case float64:
+			v[k] = strconv.FormatFloat(t, 'f', -1, 64)
+		}
+	}
+	cp := callerProfile{}
+	if len(c.Callflow) > 0 {
+		flows := []jsonCallflow{}
+		if err := json.Unmarshal(c.Callflow, &flows); err != nil {
+			f := jsonCallflow{}
+			if err = json.Unmarshal(c.Callflow, &f); err != nil {
+				return Record{}, err
+			}
+			flows = append(flows, f)
+		}
+		if len(flows) > 0 {
+			cp = flows[0].CallerProfile
+		}
+	}
+	r := record(v, cp)
+	r.Hostname = c.Switchname
+	r.Direction = c.ChannelData.Direction
+	r.Format = "json"
+	r.Raw = string(b)
+	if r.UUID == "" {
+		return Record{}, errors.New("cdr has no uuid")
+	}
+	return r, nil
+}
+
+func record(v map[string]string, cp callerProfile) Record {
+	return Record{
+		UUID:              v["uuid"],
+		CallerIDName:      cp.CallerIDName,
+		CallerIDNumber:    cp.CallerIDNumber,
+		DestinationNumber: cp.DestinationNumber,
+		Context:           cp.Context,
+		HangupCause:       v["hangup_cause"],
+		StartTime:         epoch(v["start_epoch"]),
+		AnswerTime:        epoch(v["answer_epoch"]),
+		EndTime:           epoch(v["end_epoch"]),
+		Duration:          atoi(v["duration"]),
+		Billsec:           atoi(v["billsec"]),
+	}
+}
+
+// variables are url encoded unless the cdr module has encode-values disabled
+func unescape(s string) string {
+	u, err := url.PathUnescape(s)
+	if err != nil {
+		return s
+	}
+	return u
+}
+
+func epoch(s string) time.Time {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,82 @@
+package inherit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Key is the host entry field naming the entry it inherits from
+	Key = "inherits"
+	// GroupPrefix starts the names of the entries hosts inherit from, like `group:us-east`
+	GroupPrefix = "group:"
+)
+
+// Resolve returns the module data for `hostname` with its inherited entries merged in. Entries in a
+// host map can name another entry of the same map, usually a group of hosts, under `inherits`. Objects
+// are merged key by key with the inheriting entry winning, any other value is replaced as a whole.
+// ok is false when the hostname has no entry
+func Resolve(hosts map[string]json.RawMessage, hostname string) (json.RawMessage, bool, error) {
+	if _, ok := hosts[hostname]; !ok {
+		return nil, false, nil
+	}
+	chain := []map[string]interface{}{}
+	seen := map[string]bool{}
+	for name := hostname; name != ""; {
+		if seen[name] {
+			return nil, true, fmt.Errorf("inheritance loop at [%s]", name)
+		}
+		seen[name] = true
+		d, ok := hosts[name]
+		if !ok {
+			return nil, true, fmt.Errorf("inherited entry not found [%s]", name)
+		}
+		v := map[string]interface{}{}
+		dec := json.NewDecoder(bytes.NewReader(d))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, true, err
+		}
+		chain = append(chain, v)
+		parent, ok := v[Key].(string)
+		if _, set := v[Key]; set && !ok {
+			return nil, true, errors.New("inherits must be a string")
+		}
+		delete(v, Key)
+		name = parent
+	}
+	m := map[string]interface{}{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		m = merge(m, chain[i])
+	}
+	d, err := json.Marshal(m)
+	if err != nil {
+		return nil, true, err
+	}
+	return d, true, nil
+}
+
+// Host resolves the entry of a host asking for its configuration. Groups are only there to be
+// inherited from, a hostname naming one is not found
+func Host(hosts map[string]json.RawMessage, hostname string) (json.RawMessage, bool, error) {
+	if strings.HasPrefix(hostname, GroupPrefix) {
+		return nil, false, nil
+	}
+	return Resolve(hosts, hostname)
+}
+
+func merge(base map[string]interface{}, over map[string]interface{}) map[string]interface{} {
+	for k, v := range over {
+		b, bok := base[k].(map[string]interface{})
+		o, ook := v.(map[string]interface{})
+		if bok && ook {
+			base[k] = merge(b, o)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
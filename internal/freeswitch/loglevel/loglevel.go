@@ -0,0 +1,17 @@
+package loglevel
+
+var levels = map[string]bool{
+	"console": true,
+	"alert":   true,
+	"crit":    true,
+	"err":     true,
+	"warning": true,
+	"notice":  true,
+	"info":    true,
+	"debug":   true,
+}
+
+// Valid reports whether `l` is a FreeSWITCH log level name
+func Valid(l string) bool {
+	return levels[l]
+}
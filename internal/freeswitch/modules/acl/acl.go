@@ -2,14 +2,14 @@ package acl
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"net/http"
 	"path/filepath"
 	"text/template"
 
 	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
 )
 
 const (
@@ -40,6 +40,19 @@ type module struct {
 
 type host map[string]module
 
+// lists FreeSWITCH builds itself, these can be referenced without being defined in acl.json
+var autoLists = map[string]bool{
+	"rfc1918.auto":  true,
+	"nat.auto":      true,
+	"localnet.auto": true,
+	"loopback.auto": true,
+	"wan.auto":      true,
+	"wan_v4.auto":   true,
+	"wan_v6.auto":   true,
+	"any_v4.auto":   true,
+	"any_v6.auto":   true,
+}
+
 func New(m string, t string) error {
 	moduleSettingFile = filepath.Join(m, moduleDataFile)
 	templatePath = filepath.Join(t, configTemplate)
@@ -51,21 +64,10 @@ func New(m string, t string) error {
 func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
 	rlog.Debugf("configuration request for hostname [%s]", hostname)
 
-	h := host{}
-	d, err := ioutil.ReadFile(moduleSettingFile)
+	m, err := load(hostname)
 	if err != nil {
-		rlog.Errorf("could not read file [%s]", err.Error())
 		return err
 	}
-	if err = json.Unmarshal(d, &h); err != nil {
-		rlog.Errorf("could not unmarshal file [%s]", err.Error())
-		return err
-	}
-	m, ok := h[hostname]
-	if !ok {
-		rlog.Infof("hostname not found [%s]", hostname)
-		return errors.New("hostname not found")
-	}
 	t, err := template.ParseFiles(templatePath)
 	if err != nil {
 		rlog.Errorf("could not parse template file [%s]", err.Error())
@@ -74,3 +76,48 @@ func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error
 	t.Execute(w, m)
 	return nil
 }
+
+// Exists reports whether the acl list `name` can be used by `hostname`, either because it is defined
+// for the host in acl.json or because it is one of the lists FreeSWITCH builds automatically
+func Exists(hostname string, name string) (bool, error) {
+	if autoLists[name] {
+		return true, nil
+	}
+	h, err := read(hostname)
+	if err != nil {
+		return false, err
+	}
+	for _, l := range h[hostname].Lists {
+		if l.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func read(hostname string) (host, error) {
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return h, err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return h, err
+	}
+	return h, nil
+}
+
+func load(hostname string) (module, error) {
+	h, err := read(hostname)
+	if err != nil {
+		return module{}, err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return module{}, errors.New("hostname not found")
+	}
+	return m, nil
+}
@@ -0,0 +1,202 @@
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/loglevel"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/secret"
+)
+
+const (
+	moduleDataFile = "amqp.json"
+	configTemplate = "configuration/amqp/amqp.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	exchangeTypes = map[string]bool{
+		"direct":  true,
+		"fanout":  true,
+		"topic":   true,
+		"headers": true,
+	}
+
+	// FreeSWITCH takes event names with or without the SWITCH_EVENT_ prefix
+	eventName = regexp.MustCompile(`^(SWITCH_EVENT_)?[A-Z][A-Z0-9_]*$`)
+)
+
+type settings struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type connection struct {
+	Name           string `json:"name"`
+	Hostname       string `json:"hostname"`
+	Virtualhost    string `json:"virtualhost"`
+	Username       string `json:"username"`
+	PasswordSecret string `json:"password_secret"`
+	Port           int    `json:"port"`
+	Heartbeat      int    `json:"heartbeat"`
+
+	// resolved from PasswordSecret, never read from module data
+	Password string `json:"-"`
+}
+
+type exchange struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// profile is shared by producers, commands and logging. Connections are tried in the order they are
+// listed, the first one is the primary
+type profile struct {
+	Connections []connection `json:"connections"`
+	Exchange    exchange     `json:"exchange"`
+	EventFilter []string     `json:"event_filter"`
+	LogLevels   []string     `json:"log_levels"`
+	BindingKey  string       `json:"binding_key"`
+	Params      []settings   `json:"params"`
+}
+
+// profile with its name, passed to the shared profile template
+type namedProfile struct {
+	Name    string
+	Profile profile
+}
+
+type amqp struct {
+	Producers map[string]profile `json:"producers"`
+	Commands  map[string]profile `json:"commands"`
+	Logging   map[string]profile `json:"logging"`
+}
+
+type module struct {
+	AMQP amqp `json:"amqp.conf"`
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_amqp")
+	rlog.Infof("set amqp module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set amqp template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	m := module{}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return err
+	}
+	if err = validate(m.AMQP); err != nil {
+		rlog.Errorf("invalid amqp.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	for _, profiles := range []map[string]profile{m.AMQP.Producers, m.AMQP.Commands, m.AMQP.Logging} {
+		for _, p := range profiles {
+			for i := range p.Connections {
+				c := &p.Connections[i]
+				if c.Password, err = secret.Get(c.PasswordSecret); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	t, err := template.New(filepath.Base(templatePath)).Funcs(template.FuncMap{
+		"join": strings.Join,
+		"profile": func(name string, p profile) namedProfile {
+			return namedProfile{Name: name, Profile: p}
+		},
+	}).ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.AMQP)
+	return nil
+}
+
+func validate(a amqp) error {
+	for kind, profiles := range map[string]map[string]profile{"producers": a.Producers, "commands": a.Commands, "logging": a.Logging} {
+		for pn, p := range profiles {
+			if len(p.Connections) == 0 {
+				return fmt.Errorf("profile has no connections [%s] [%s]", kind, pn)
+			}
+			seen := map[string]bool{}
+			for _, c := range p.Connections {
+				cn := c.Name
+				if cn == "" {
+					return fmt.Errorf("connection name not set [%s] [%s]", kind, pn)
+				}
+				if seen[cn] {
+					return fmt.Errorf("duplicate connection [%s] [%s] [%s]", kind, pn, cn)
+				}
+				seen[cn] = true
+				if c.Hostname == "" || c.Username == "" || c.PasswordSecret == "" {
+					return fmt.Errorf("connection needs hostname, username and password secret [%s] [%s] [%s]", kind, pn, cn)
+				}
+				if c.Port < 1 || c.Port > 65535 {
+					return fmt.Errorf("invalid connection port [%s] [%s] [%s] [%d]", kind, pn, cn, c.Port)
+				}
+				if c.Heartbeat < 0 {
+					return fmt.Errorf("heartbeat can not be negative [%s] [%s] [%s]", kind, pn, cn)
+				}
+			}
+			if p.Exchange.Name == "" {
+				return fmt.Errorf("exchange name not set [%s] [%s]", kind, pn)
+			}
+			if p.Exchange.Type != "" && !exchangeTypes[p.Exchange.Type] {
+				return fmt.Errorf("unknown exchange type [%s] [%s] [%s]", kind, pn, p.Exchange.Type)
+			}
+			for _, e := range p.EventFilter {
+				if !eventName.MatchString(e) {
+					return fmt.Errorf("invalid event name in event filter [%s] [%s] [%s]", kind, pn, e)
+				}
+			}
+			for _, l := range p.LogLevels {
+				if !loglevel.Valid(l) {
+					return fmt.Errorf("unknown log level [%s] [%s] [%s]", kind, pn, l)
+				}
+			}
+		}
+	}
+	return nil
+}
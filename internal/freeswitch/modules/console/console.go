@@ -0,0 +1,102 @@
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/loglevel"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "console.json"
+	configTemplate = "configuration/console/console.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+)
+
+type console struct {
+	Colorize bool                `json:"colorize"`
+	Loglevel string              `json:"loglevel"`
+	Mappings map[string][]string `json:"mappings"`
+}
+
+type module struct {
+	Console console `json:"console.conf"`
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_console")
+	rlog.Infof("set console module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set console template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	m := module{}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return err
+	}
+	if err = validate(m.Console); err != nil {
+		rlog.Errorf("invalid console.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	t, err := template.New(filepath.Base(templatePath)).Funcs(template.FuncMap{"join": strings.Join}).ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Console)
+	return nil
+}
+
+func validate(c console) error {
+	if c.Loglevel != "" && !loglevel.Valid(c.Loglevel) {
+		return fmt.Errorf("unknown log level [%s]", c.Loglevel)
+	}
+	for n, levels := range c.Mappings {
+		for _, v := range levels {
+			if !loglevel.Valid(v) {
+				return fmt.Errorf("unknown log level [%s] [%s]", n, v)
+			}
+		}
+	}
+	return nil
+}
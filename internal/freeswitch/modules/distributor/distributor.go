@@ -2,14 +2,15 @@ package distributor
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"net/http"
 	"path/filepath"
 	"text/template"
 
 	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
 )
 
 const (
@@ -42,6 +43,7 @@ type host map[string]module
 func New(m string, t string) error {
 	moduleSettingFile = filepath.Join(m, moduleDataFile)
 	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_distributor")
 	rlog.Infof("set distributor module settings file [%s]", moduleSettingFile)
 	rlog.Infof("set distributor template path [%s]", templatePath)
 	return nil
@@ -51,12 +53,12 @@ func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error
 	rlog.Debugf("configuration request for hostname [%s]", hostname)
 
 	h := host{}
-	d, err := ioutil.ReadFile(moduleSettingFile)
+	d, err := moduledata.Read(moduleSettingFile, hostname)
 	if err != nil {
 		rlog.Errorf("could not read file [%s]", err.Error())
 		return err
 	}
-	if err = json.Unmarshal(d, &h); err != nil {
+	if err = moduledata.Unmarshal(d, &h); err != nil {
 		rlog.Errorf("could not unmarshal file [%s]", err.Error())
 		return err
 	}
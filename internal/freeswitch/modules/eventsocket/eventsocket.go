@@ -0,0 +1,100 @@
+package eventsocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/acl"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/secret"
+)
+
+const (
+	moduleDataFile = "event_socket.json"
+	configTemplate = "configuration/event_socket/event_socket.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+)
+
+type settings struct {
+	ListenIP        string `json:"listen_ip"`
+	ListenPort      int    `json:"listen_port"`
+	PasswordSecret  string `json:"password_secret"`
+	ApplyInboundACL string `json:"apply_inbound_acl"`
+
+	// resolved from PasswordSecret, never read from module data
+	Password string `json:"-"`
+}
+
+type module struct {
+	Settings settings `json:"event_socket.conf"`
+}
+
+type host map[string]module
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_event_socket")
+	rlog.Infof("set event_socket module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set event_socket template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	if m.Settings.ListenPort < 1 || m.Settings.ListenPort > 65535 {
+		rlog.Errorf("invalid listen port [%d]", m.Settings.ListenPort)
+		return errors.New("invalid listen port")
+	}
+	if m.Settings.PasswordSecret == "" {
+		rlog.Errorf("password secret not set for hostname [%s]", hostname)
+		return errors.New("password secret not set")
+	}
+	m.Settings.Password, err = secret.Get(m.Settings.PasswordSecret)
+	if err != nil {
+		return err
+	}
+	if m.Settings.ApplyInboundACL != "" {
+		ok, err := acl.Exists(hostname, m.Settings.ApplyInboundACL)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			rlog.Errorf("acl list not defined for hostname [%s] [%s]", hostname, m.Settings.ApplyInboundACL)
+			return errors.New("acl list not defined")
+		}
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Settings)
+	return nil
+}
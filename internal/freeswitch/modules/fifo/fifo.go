@@ -0,0 +1,149 @@
+package fifo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "fifo.json"
+	configTemplate = "configuration/fifo/fifo.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	outboundStrategies = map[string]bool{
+		"ringall":    true,
+		"enterprise": true,
+	}
+)
+
+type settings struct {
+	DeleteAllOutboundMemberOnStartup bool   `json:"delete_all_outbound_member_on_startup"`
+	OutboundStrategy                 string `json:"outbound_strategy"`
+}
+
+type member struct {
+	DialString string `json:"dial_string"`
+	Timeout    int    `json:"timeout"`
+	Simo       int    `json:"simo"`
+	Lag        int    `json:"lag"`
+}
+
+type queue struct {
+	Name       string   `json:"name"`
+	Importance int      `json:"importance"`
+	Members    []member `json:"members"`
+}
+
+type fifo struct {
+	Settings settings `json:"settings"`
+	Fifos    []queue  `json:"fifos"`
+}
+
+type module struct {
+	Fifo fifo `json:"fifo.conf"`
+}
+
+type host map[string]module
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_fifo")
+	rlog.Infof("set fifo module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set fifo template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h, err := read(hostname)
+	if err != nil {
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	if err = validate(m.Fifo); err != nil {
+		rlog.Errorf("invalid fifo.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Fifo)
+	return nil
+}
+
+// Exists reports whether the fifo queue `name` is defined for `hostname`
+func Exists(hostname string, name string) (bool, error) {
+	h, err := read(hostname)
+	if err != nil {
+		return false, err
+	}
+	for _, q := range h[hostname].Fifo.Fifos {
+		if q.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func validate(f fifo) error {
+	if f.Settings.OutboundStrategy != "" && !outboundStrategies[f.Settings.OutboundStrategy] {
+		return fmt.Errorf("unknown outbound strategy [%s]", f.Settings.OutboundStrategy)
+	}
+	names := map[string]bool{}
+	for _, q := range f.Fifos {
+		if q.Name == "" {
+			return errors.New("fifo name not set")
+		}
+		if names[q.Name] {
+			return fmt.Errorf("duplicate fifo [%s]", q.Name)
+		}
+		names[q.Name] = true
+		if q.Importance < 0 {
+			return fmt.Errorf("importance can not be negative [%s]", q.Name)
+		}
+		for _, m := range q.Members {
+			if m.DialString == "" {
+				return fmt.Errorf("member dial string not set [%s]", q.Name)
+			}
+			if m.Timeout < 0 || m.Simo < 0 || m.Lag < 0 {
+				return fmt.Errorf("member timeout, simo and lag can not be negative [%s] [%s]", q.Name, m.DialString)
+			}
+		}
+	}
+	return nil
+}
+
+func read(hostname string) (host, error) {
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return h, err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return h, err
+	}
+	return h, nil
+}
@@ -0,0 +1,254 @@
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+// Conf declares a configuration served from module data without a dedicated module
+type Conf struct {
+	Name           string `json:"name"`
+	ModuleDataFile string `json:"module_data_file"`
+	Template       string `json:"template"`
+	// FreeSWITCH module reading the conf, mod_<name> when not set
+	Module string `json:"module"`
+}
+
+type conf struct {
+	moduleSettingFile string
+	templatePath      string
+}
+
+// configuration passed to the template, Data is the free-form module data for the host
+type rendered struct {
+	Name string
+	Data interface{}
+}
+
+var (
+	confs = map[string]conf{}
+
+	funcs = template.FuncMap{
+		"elements": elements,
+	}
+
+	// element text only needs markup escaped, quotes are left readable
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+	// tags and attribute names are written as they are, so they are limited to plain xml names
+	xmlName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.:-]*$`)
+)
+
+func New(m string, t string, c []Conf) error {
+	confs = map[string]conf{}
+	for _, g := range c {
+		if g.Name == "" || g.ModuleDataFile == "" || g.Template == "" {
+			return fmt.Errorf("generic module needs a name, module data file and template [%s]", g.Name)
+		}
+		if _, ok := confs[g.Name]; ok {
+			return fmt.Errorf("duplicate generic module [%s]", g.Name)
+		}
+		mod := g.Module
+		if mod == "" {
+			mod = "mod_" + strings.TrimSuffix(g.Name, ".conf")
+		}
+		modules.Uses(g.ModuleDataFile, mod)
+		confs[g.Name] = conf{
+			moduleSettingFile: filepath.Join(m, g.ModuleDataFile),
+			templatePath:      filepath.Join(t, g.Template),
+		}
+		rlog.Infof("set generic module [%s] settings file [%s]", g.Name, confs[g.Name].moduleSettingFile)
+		rlog.Infof("set generic module [%s] template path [%s]", g.Name, confs[g.Name].templatePath)
+	}
+	return nil
+}
+
+// Handles reports whether `name` is served by a generic module
+func Handles(name string) bool {
+	_, ok := confs[name]
+	return ok
+}
+
+func Handler(ctx context.Context, name string, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s] [%s]", hostname, name)
+
+	c, ok := confs[name]
+	if !ok {
+		return errors.New("generic module not found")
+	}
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(c.moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	m := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(r))
+	dec.UseNumber()
+	if err = dec.Decode(&m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return err
+	}
+	v, ok := m[name]
+	if !ok {
+		rlog.Infof("hostname has no data for module [%s] [%s]", hostname, name)
+		return errors.New("hostname not found")
+	}
+	t, err := template.New(filepath.Base(c.templatePath)).Funcs(funcs).ParseFiles(c.templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	// rendered before anything is sent, an invalid element must not leave a truncated document
+	b := &bytes.Buffer{}
+	if err = t.Execute(b, rendered{Name: name, Data: v}); err != nil {
+		rlog.Errorf("could not render module data for hostname [%s] [%s] [%s]", hostname, name, err.Error())
+		return err
+	}
+	b.WriteTo(w)
+	return nil
+}
+
+// elements renders a list of free-form elements as indented xml, starting `depth` levels deep. Each
+// element is an object with a `tag`, optional `attributes`, `params` rendered as <param name value/>
+// children, nested `elements` or `text`, which can not be combined with params or elements
+func elements(v interface{}, depth int) (string, error) {
+	l, ok := v.([]interface{})
+	if !ok {
+		if v == nil {
+			return "", nil
+		}
+		return "", errors.New("elements must be a list")
+	}
+	b := &strings.Builder{}
+	for _, e := range l {
+		if err := element(b, e, depth); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func element(b *strings.Builder, v interface{}, depth int) error {
+	e, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.New("element must be an object")
+	}
+	tag, _ := e["tag"].(string)
+	if tag == "" {
+		return errors.New("element tag not set")
+	}
+	if !xmlName.MatchString(tag) {
+		return fmt.Errorf("element tag is not an xml name [%s]", tag)
+	}
+	indent := strings.Repeat("    ", depth)
+	b.WriteString(indent + "<" + tag)
+	if a, ok := e["attributes"].(map[string]interface{}); ok {
+		keys := make([]string, 0, len(a))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		// name first so the rendered elements read like hand written FreeSWITCH configuration
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i] == "name" || keys[j] == "name" {
+				return keys[i] == "name"
+			}
+			return keys[i] < keys[j]
+		})
+		for _, k := range keys {
+			if !xmlName.MatchString(k) {
+				return fmt.Errorf("attribute is not an xml name [%s] [%s]", tag, k)
+			}
+			v, ok := scalar(a[k])
+			if !ok {
+				return fmt.Errorf("attribute value must be a string, number or boolean [%s] [%s]", tag, k)
+			}
+			fmt.Fprintf(b, ` %s="%s"`, k, html.EscapeString(v))
+		}
+	}
+	params, _ := e["params"].([]interface{})
+	children, _ := e["elements"].([]interface{})
+	text, hasText := e["text"]
+	if len(params) == 0 && len(children) == 0 && !hasText {
+		b.WriteString("/>\n")
+		return nil
+	}
+	if hasText && (len(params) > 0 || len(children) > 0) {
+		return fmt.Errorf("element can not have text together with params or elements [%s]", tag)
+	}
+	if hasText {
+		t, ok := scalar(text)
+		if !ok {
+			return fmt.Errorf("element text must be a string, number or boolean [%s]", tag)
+		}
+		fmt.Fprintf(b, ">%s</%s>\n", textEscaper.Replace(t), tag)
+		return nil
+	}
+	b.WriteString(">\n")
+	for _, p := range params {
+		pm, ok := p.(map[string]interface{})
+		if !ok {
+			return errors.New("param must be an object")
+		}
+		name, _ := pm["name"].(string)
+		if name == "" {
+			return fmt.Errorf("param name not set [%s]", tag)
+		}
+		value, ok := scalar(pm["value"])
+		if !ok {
+			return fmt.Errorf("param value must be a string, number or boolean [%s] [%s]", tag, name)
+		}
+		fmt.Fprintf(b, "%s    <param name=\"%s\" value=\"%s\"/>\n", indent, html.EscapeString(name), html.EscapeString(value))
+	}
+	for _, c := range children {
+		if err := element(b, c, depth+1); err != nil {
+			return err
+		}
+	}
+	b.WriteString(indent + "</" + tag + ">\n")
+	return nil
+}
+
+// scalar returns a string, number or boolean of the module data as written, anything else, like a
+// missing value, is not rendered
+func scalar(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case json.Number:
+		return t.String(), true
+	case bool:
+		return strconv.FormatBool(t), true
+	}
+	return "", false
+}
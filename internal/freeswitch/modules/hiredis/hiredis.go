@@ -0,0 +1,152 @@
+package hiredis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/secret"
+)
+
+const (
+	moduleDataFile = "hiredis.json"
+	configTemplate = "configuration/hiredis/hiredis.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+)
+
+type connection struct {
+	Name           string `json:"name"`
+	Hostname       string `json:"hostname"`
+	PasswordSecret string `json:"password_secret"`
+	Port           int    `json:"port"`
+	TimeoutMS      int    `json:"timeout_ms"`
+
+	// resolved from PasswordSecret, never read from module data
+	Password string `json:"-"`
+}
+
+type params struct {
+	IgnoreConnectFail    bool `json:"ignore_connect_fail"`
+	IgnoreError          bool `json:"ignore_error"`
+	MaxPipelinedRequests int  `json:"max_pipelined_requests"`
+	DeleteWhenZero       bool `json:"delete_when_zero"`
+}
+
+// connections are tried in the order they are listed, the first one is the primary
+type profile struct {
+	Connections []connection `json:"connections"`
+	Params      params       `json:"params"`
+}
+
+type hiredis struct {
+	Profiles map[string]profile `json:"profiles"`
+}
+
+type module struct {
+	Hiredis hiredis `json:"hiredis.conf"`
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_hiredis")
+	rlog.Infof("set hiredis module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set hiredis template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	m := module{}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return err
+	}
+	if err = validate(m.Hiredis); err != nil {
+		rlog.Errorf("invalid hiredis.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	for _, p := range m.Hiredis.Profiles {
+		for i := range p.Connections {
+			c := &p.Connections[i]
+			if c.PasswordSecret == "" {
+				continue
+			}
+			if c.Password, err = secret.Get(c.PasswordSecret); err != nil {
+				return err
+			}
+		}
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Hiredis)
+	return nil
+}
+
+func validate(h hiredis) error {
+	for pn, p := range h.Profiles {
+		if len(p.Connections) == 0 {
+			return fmt.Errorf("profile has no connections [%s]", pn)
+		}
+		if p.Params.MaxPipelinedRequests < 0 {
+			return fmt.Errorf("max-pipelined-requests can not be negative [%s]", pn)
+		}
+		seen := map[string]bool{}
+		for _, c := range p.Connections {
+			cn := c.Name
+			if cn == "" {
+				return fmt.Errorf("connection name not set [%s]", pn)
+			}
+			if seen[cn] {
+				return fmt.Errorf("duplicate connection [%s] [%s]", pn, cn)
+			}
+			seen[cn] = true
+			if c.Hostname == "" {
+				return fmt.Errorf("connection hostname not set [%s] [%s]", pn, cn)
+			}
+			if c.Port < 1 || c.Port > 65535 {
+				return fmt.Errorf("invalid connection port [%s] [%s] [%d]", pn, cn, c.Port)
+			}
+			if c.TimeoutMS < 0 {
+				return fmt.Errorf("connection timeout can not be negative [%s] [%s]", pn, cn)
+			}
+		}
+	}
+	return nil
+}
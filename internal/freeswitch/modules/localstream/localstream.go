@@ -0,0 +1,137 @@
+package localstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "local_stream.json"
+	configTemplate = "configuration/local_stream/local_stream.xml"
+
+	// prefix of the file strings played from a local stream
+	URLPrefix = "local_stream://"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	// rates mod_local_stream serves a directory at
+	rates = map[int]bool{
+		8000:  true,
+		11025: true,
+		16000: true,
+		22050: true,
+		32000: true,
+		44100: true,
+		48000: true,
+	}
+)
+
+type directory struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Rate      int    `json:"rate"`
+	Shuffle   bool   `json:"shuffle"`
+	Channels  int    `json:"channels"`
+	Interval  int    `json:"interval"`
+	TimerName string `json:"timer_name"`
+}
+
+type module struct {
+	Directories []directory `json:"local_stream.conf"`
+}
+
+type host map[string]module
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_local_stream")
+	rlog.Infof("set local_stream module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set local_stream template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h, err := read(hostname)
+	if err != nil {
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	for _, d := range m.Directories {
+		if err = validate(d); err != nil {
+			rlog.Errorf("invalid local_stream directory for hostname [%s] [%s]", hostname, err.Error())
+			return err
+		}
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m)
+	return nil
+}
+
+// Exists reports whether the stream `name` is defined for `hostname`. A name without a rate also
+// matches the `name/<rate>` directories, mod_local_stream picks the one matching the channel rate
+func Exists(hostname string, name string) (bool, error) {
+	h, err := read(hostname)
+	if err != nil {
+		return false, err
+	}
+	for _, d := range h[hostname].Directories {
+		if d.Name == name || strings.HasPrefix(d.Name, name+"/") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func validate(d directory) error {
+	if d.Name == "" || d.Path == "" {
+		return errors.New("directory name and path must be set")
+	}
+	if d.Rate != 0 && !rates[d.Rate] {
+		return fmt.Errorf("unsupported rate [%s] [%d]", d.Name, d.Rate)
+	}
+	if d.Channels < 0 || d.Channels > 2 {
+		return fmt.Errorf("channels must be 1 or 2 [%s] [%d]", d.Name, d.Channels)
+	}
+	if d.Interval != 0 && (d.Interval < 10 || d.Interval > 120 || d.Interval%10 != 0) {
+		return fmt.Errorf("interval must be a multiple of 10 between 10-120 [%s] [%d]", d.Name, d.Interval)
+	}
+	return nil
+}
+
+func read(hostname string) (host, error) {
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return h, err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return h, err
+	}
+	return h, nil
+}
@@ -0,0 +1,111 @@
+package logfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/loglevel"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "logfile.json"
+	configTemplate = "configuration/logfile/logfile.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+)
+
+type profile struct {
+	Logfile       string              `json:"logfile"`
+	Rollover      int64               `json:"rollover"`
+	MaximumRotate int                 `json:"maximum_rotate"`
+	UUID          bool                `json:"uuid"`
+	Mappings      map[string][]string `json:"mappings"`
+}
+
+type logfile struct {
+	RotateOnHup bool               `json:"rotate_on_hup"`
+	Profiles    map[string]profile `json:"profiles"`
+}
+
+type module struct {
+	Logfile logfile `json:"logfile.conf"`
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_logfile")
+	rlog.Infof("set logfile module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set logfile template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	m := module{}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return err
+	}
+	if err = validate(m.Logfile); err != nil {
+		rlog.Errorf("invalid logfile.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	t, err := template.New(filepath.Base(templatePath)).Funcs(template.FuncMap{"join": strings.Join}).ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Logfile)
+	return nil
+}
+
+func validate(l logfile) error {
+	for n, p := range l.Profiles {
+		if p.Rollover < 0 || p.MaximumRotate < 0 {
+			return fmt.Errorf("rollover and maximum-rotate can not be negative [%s]", n)
+		}
+		for mn, levels := range p.Mappings {
+			for _, v := range levels {
+				if !loglevel.Valid(v) {
+					return fmt.Errorf("unknown log level [%s] [%s] [%s]", n, mn, v)
+				}
+			}
+		}
+	}
+	return nil
+}
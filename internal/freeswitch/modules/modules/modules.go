@@ -0,0 +1,163 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "modules.json"
+	configTemplate = "configuration/modules/modules.xml"
+)
+
+var (
+	moduleDataDirectory string
+	moduleSettingFile   string
+	templatePath        string
+
+	// module data files and the FreeSWITCH module that has to be loaded for them to be used, set by the
+	// modules serving them
+	dataFileModules = map[string]string{}
+)
+
+type load struct {
+	Module   string `json:"module"`
+	Critical bool   `json:"critical"`
+	// drops a module inherited from the role
+	Disabled bool `json:"disabled"`
+}
+
+type hostModules struct {
+	Role    string `json:"role"`
+	Modules []load `json:"modules"`
+}
+
+type module struct {
+	Modules hostModules `json:"modules.conf"`
+}
+
+type settings struct {
+	Roles map[string][]load `json:"roles"`
+	Hosts map[string]module `json:"hosts"`
+}
+
+func New(m string, t string) error {
+	moduleDataDirectory = m
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	rlog.Infof("set modules module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set modules template path [%s]", templatePath)
+	return nil
+}
+
+// Uses records that the module data file `f`, relative to the module data directory, is only used
+// when FreeSWITCH loads `module`
+func Uses(f string, module string) {
+	dataFileModules[f] = module
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	s := settings{}
+	d, err := moduledata.ReadFile(moduleSettingFile)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &s); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	m, ok := s.Hosts[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	var base []load
+	if m.Modules.Role != "" {
+		base, ok = s.Roles[m.Modules.Role]
+		if !ok {
+			rlog.Errorf("role not found [%s]", m.Modules.Role)
+			return errors.New("role not found")
+		}
+	}
+	l := merge(base, m.Modules.Modules)
+	checkDataFiles(hostname, l)
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, l)
+	return nil
+}
+
+// merge returns the role list with the host entries applied. A host entry for a module already in the
+// role replaces it in place, so the role order is kept, other host entries are appended in order
+func merge(base []load, host []load) []load {
+	l := make([]load, 0, len(base)+len(host))
+	i := map[string]int{}
+	for _, b := range base {
+		if _, ok := i[b.Module]; ok {
+			continue
+		}
+		i[b.Module] = len(l)
+		l = append(l, b)
+	}
+	for _, h := range host {
+		if n, ok := i[h.Module]; ok {
+			l[n] = h
+			continue
+		}
+		i[h.Module] = len(l)
+		l = append(l, h)
+	}
+	r := l[:0]
+	for _, m := range l {
+		if !m.Disabled {
+			r = append(r, m)
+		}
+	}
+	return r
+}
+
+// checkDataFiles warns about module data defined for the host that FreeSWITCH will never request
+// because the module using it is not in the load list. The host gets the data like the module would,
+// from its host file or inherited from a group
+func checkDataFiles(hostname string, l []load) {
+	loaded := map[string]bool{}
+	for _, m := range l {
+		loaded[m.Module] = true
+	}
+	for f, mod := range dataFileModules {
+		if loaded[mod] {
+			continue
+		}
+		h := map[string]json.RawMessage{}
+		d, err := moduledata.Read(filepath.Join(moduleDataDirectory, f), hostname)
+		if err != nil {
+			continue
+		}
+		if err = moduledata.Unmarshal(d, &h); err != nil {
+			continue
+		}
+		r, ok, err := inherit.Host(h, hostname)
+		if err != nil || !ok {
+			continue
+		}
+		e := map[string]json.RawMessage{}
+		if err = json.Unmarshal(r, &e); err == nil && len(e) > 0 {
+			rlog.Warnf("hostname has data in [%s] but [%s] is not loaded [%s]", f, mod, hostname)
+		}
+	}
+}
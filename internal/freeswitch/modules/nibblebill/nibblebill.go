@@ -0,0 +1,116 @@
+package nibblebill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/secret"
+)
+
+const (
+	moduleDataFile = "nibblebill.json"
+	configTemplate = "configuration/nibblebill/nibblebill.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+)
+
+type nibblebill struct {
+	DSNSecret       string  `json:"dsn_secret"`
+	DBTable         string  `json:"db_table"`
+	DBColumnCash    string  `json:"db_column_cash"`
+	DBColumnAccount string  `json:"db_column_account"`
+	GlobalHeartbeat int     `json:"global_heartbeat"`
+	LowbalAmt       float64 `json:"lowbal_amt"`
+	LowbalAction    string  `json:"lowbal_action"`
+	NobalAmt        float64 `json:"nobal_amt"`
+	NobalAction     string  `json:"nobal_action"`
+
+	// resolved from DSNSecret, never read from module data
+	DSN string `json:"-"`
+}
+
+type module struct {
+	Nibblebill nibblebill `json:"nibblebill.conf"`
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_nibblebill")
+	rlog.Infof("set nibblebill module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set nibblebill template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	m := module{}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return err
+	}
+	if err = validate(m.Nibblebill); err != nil {
+		rlog.Errorf("invalid nibblebill.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	m.Nibblebill.DSN, err = secret.Get(m.Nibblebill.DSNSecret)
+	if err != nil {
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Nibblebill)
+	return nil
+}
+
+func validate(n nibblebill) error {
+	if n.DSNSecret == "" {
+		return errors.New("dsn secret not set")
+	}
+	if n.DBTable == "" || n.DBColumnCash == "" || n.DBColumnAccount == "" {
+		return errors.New("db_table, db_column_cash and db_column_account must be set")
+	}
+	if n.GlobalHeartbeat < 0 {
+		return fmt.Errorf("global_heartbeat can not be negative [%d]", n.GlobalHeartbeat)
+	}
+	if n.NobalAmt > n.LowbalAmt {
+		return fmt.Errorf("nobal_amt must not be above lowbal_amt [%v] [%v]", n.NobalAmt, n.LowbalAmt)
+	}
+	return nil
+}
@@ -2,14 +2,17 @@ package sofia
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/localstream"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
 )
 
 const (
@@ -52,6 +55,7 @@ type host map[string]module
 func New(m string, t string) error {
 	moduleSettingFile = filepath.Join(m, moduleDataFile)
 	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_sofia")
 	rlog.Infof("set module settings file [%s]", moduleSettingFile)
 	rlog.Infof("set template path [%s]", templatePath)
 	return nil
@@ -60,14 +64,8 @@ func New(m string, t string) error {
 func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
 	rlog.Debugf("configuration request for hostname [%s]", hostname)
 
-	h := host{}
-	d, err := ioutil.ReadFile(moduleSettingFile)
+	h, err := read(hostname)
 	if err != nil {
-		rlog.Errorf("could not read file [%s]", err.Error())
-		return err
-	}
-	if err = json.Unmarshal(d, &h); err != nil {
-		rlog.Errorf("could not unmarshal file [%s]", err.Error())
 		return err
 	}
 	m, ok := h[hostname]
@@ -75,6 +73,9 @@ func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error
 		rlog.Infof("hostname not found [%s]", hostname)
 		return errors.New("hostname not found")
 	}
+	if err = checkLocalStreams(hostname, m.Sofia); err != nil {
+		return err
+	}
 	t, err := template.ParseFiles(templatePath)
 	if err != nil {
 		rlog.Errorf("could not parse template file [%s]", err.Error())
@@ -83,3 +84,59 @@ func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error
 	t.Execute(w, m.Sofia)
 	return nil
 }
+
+// Gateways returns the names of the gateways defined by the profiles of `hostname`
+func Gateways(hostname string) (map[string]bool, error) {
+	h, err := read(hostname)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, p := range h[hostname].Sofia.Profiles {
+		for _, g := range p.Gateways {
+			names[g.Name] = true
+		}
+	}
+	return names, nil
+}
+
+// checkLocalStreams makes sure every `local_stream://` setting, like hold-music, points at a stream
+// defined for the host in local_stream.json
+func checkLocalStreams(hostname string, s sofia) error {
+	all := append([]settings{}, s.Globals...)
+	for _, p := range s.Profiles {
+		all = append(all, p.Settings...)
+		for _, g := range p.Gateways {
+			all = append(all, g.Settings...)
+		}
+	}
+	for _, v := range all {
+		if !strings.HasPrefix(v.Value, localstream.URLPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(v.Value, localstream.URLPrefix)
+		ok, err := localstream.Exists(hostname, name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			rlog.Errorf("local stream not defined for hostname [%s] [%s] [%s]", hostname, v.Name, name)
+			return errors.New("local stream not defined")
+		}
+	}
+	return nil
+}
+
+func read(hostname string) (host, error) {
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return h, err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return h, err
+	}
+	return h, nil
+}
@@ -0,0 +1,118 @@
+package spandsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "spandsp.json"
+	configTemplate = "configuration/spandsp/spandsp.xml"
+
+	// the T.30 local identifier is limited to 20 characters
+	maxIdentLength = 20
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+)
+
+type fax struct {
+	UseECM     bool   `json:"use_ecm"`
+	Verbose    bool   `json:"verbose"`
+	Ident      string `json:"ident"`
+	Header     string `json:"header"`
+	SpoolDir   string `json:"spool_dir"`
+	FilePrefix string `json:"file_prefix"`
+}
+
+type t38 struct {
+	Enabled bool `json:"enabled"`
+	Request bool `json:"request"`
+}
+
+type modem struct {
+	TotalModems int    `json:"total_modems"`
+	Directory   string `json:"directory"`
+	Dialplan    string `json:"dialplan"`
+	Context     string `json:"context"`
+	Verbose     bool   `json:"verbose"`
+}
+
+type spandsp struct {
+	Fax   fax   `json:"fax"`
+	T38   t38   `json:"t38"`
+	Modem modem `json:"modem"`
+}
+
+type module struct {
+	Spandsp spandsp `json:"spandsp.conf"`
+}
+
+type host map[string]module
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_spandsp")
+	rlog.Infof("set spandsp module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set spandsp template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	if err = validate(m.Spandsp); err != nil {
+		rlog.Errorf("invalid spandsp.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Spandsp)
+	return nil
+}
+
+func validate(s spandsp) error {
+	if len(s.Fax.Ident) > maxIdentLength {
+		return fmt.Errorf("fax ident longer than %d characters [%s]", maxIdentLength, s.Fax.Ident)
+	}
+	if s.T38.Request && !s.T38.Enabled {
+		return errors.New("t38 request needs t38 enabled")
+	}
+	if s.Modem.TotalModems < 0 {
+		return fmt.Errorf("total modems can not be negative [%d]", s.Modem.TotalModems)
+	}
+	if s.Modem.TotalModems > 0 && s.Modem.Directory == "" {
+		return errors.New("modem directory not set")
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package switchconf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/loglevel"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "switch.json"
+	configTemplate = "configuration/switch/switch.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	// switch.conf params rendered from the typed core settings
+	typedParams = map[string]bool{
+		"switchname":          true,
+		"colorize-console":    true,
+		"dump-cores":          true,
+		"loglevel":            true,
+		"debug-level":         true,
+		"max-sessions":        true,
+		"sessions-per-second": true,
+		"min-idle-cpu":        true,
+		"max-db-handles":      true,
+		"db-handle-timeout":   true,
+		"rtp-start-port":      true,
+		"rtp-end-port":        true,
+		"core-db-dsn":         true,
+	}
+)
+
+type param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type keybinding struct {
+	Name  int    `json:"name"`
+	Value string `json:"value"`
+}
+
+type ptime struct {
+	Codec string `json:"codec"`
+	Ptime int    `json:"ptime"`
+}
+
+// core settings, unset fields are left out of the rendered configuration so FreeSWITCH uses its default
+type coreSettings struct {
+	MaxSessions       *int    `json:"max_sessions"`
+	SessionsPerSecond *int    `json:"sessions_per_second"`
+	RTPStartPort      *int    `json:"rtp_start_port"`
+	RTPEndPort        *int    `json:"rtp_end_port"`
+	MinIdleCPU        *int    `json:"min_idle_cpu"`
+	MaxDBHandles      *int    `json:"max_db_handles"`
+	DBHandleTimeout   *int    `json:"db_handle_timeout"`
+	Loglevel          *string `json:"loglevel"`
+	DebugLevel        *int    `json:"debug_level"`
+	Colorize          *bool   `json:"colorize_console"`
+	DumpCores         *bool   `json:"dump_cores"`
+	CoreDBDSN         *string `json:"core_db_dsn"`
+	SwitchName        *string `json:"switchname"`
+}
+
+type switchConf struct {
+	CLIKeybindings []keybinding `json:"cli_keybindings"`
+	DefaultPtimes  []ptime      `json:"default_ptimes"`
+	Settings       coreSettings `json:"settings"`
+	Params         []param      `json:"params"`
+}
+
+type module struct {
+	Switch switchConf `json:"switch.conf"`
+}
+
+type host map[string]module
+
+// configuration passed to the template
+type rendered struct {
+	CLIKeybindings []keybinding
+	DefaultPtimes  []ptime
+	Settings       []param
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	rlog.Infof("set switch module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set switch template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	if err = validate(m.Switch); err != nil {
+		rlog.Errorf("invalid switch.conf for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, rendered{
+		CLIKeybindings: m.Switch.CLIKeybindings,
+		DefaultPtimes:  m.Switch.DefaultPtimes,
+		Settings:       append(m.Switch.Settings.params(), m.Switch.Params...),
+	})
+	return nil
+}
+
+func validate(s switchConf) error {
+	for _, k := range s.CLIKeybindings {
+		if k.Name < 1 || k.Name > 12 {
+			return fmt.Errorf("cli keybinding must be 1-12 [%d]", k.Name)
+		}
+	}
+	for _, p := range s.DefaultPtimes {
+		if p.Codec == "" {
+			return errors.New("default ptime codec not set")
+		}
+		if p.Ptime < 10 || p.Ptime > 120 || p.Ptime%10 != 0 {
+			return fmt.Errorf("default ptime must be a multiple of 10 between 10-120 [%s] [%d]", p.Codec, p.Ptime)
+		}
+	}
+	// free form params would render next to the typed settings without their checks
+	for _, p := range s.Params {
+		if typedParams[strings.ToLower(p.Name)] {
+			return fmt.Errorf("param is a typed setting, set it in settings [%s]", p.Name)
+		}
+	}
+	c := s.Settings
+	if err := checkRange("max-sessions", c.MaxSessions, 1, 1000000); err != nil {
+		return err
+	}
+	if err := checkRange("sessions-per-second", c.SessionsPerSecond, 1, 10000); err != nil {
+		return err
+	}
+	if err := checkRange("rtp-start-port", c.RTPStartPort, 1024, 65535); err != nil {
+		return err
+	}
+	if err := checkRange("rtp-end-port", c.RTPEndPort, 1024, 65535); err != nil {
+		return err
+	}
+	if c.RTPStartPort != nil && c.RTPEndPort != nil && *c.RTPStartPort >= *c.RTPEndPort {
+		return fmt.Errorf("rtp-start-port must be lower than rtp-end-port [%d] [%d]", *c.RTPStartPort, *c.RTPEndPort)
+	}
+	if err := checkRange("min-idle-cpu", c.MinIdleCPU, 0, 100); err != nil {
+		return err
+	}
+	if err := checkRange("max-db-handles", c.MaxDBHandles, 1, 5000); err != nil {
+		return err
+	}
+	if err := checkRange("db-handle-timeout", c.DBHandleTimeout, 1, 5000); err != nil {
+		return err
+	}
+	if err := checkRange("debug-level", c.DebugLevel, 0, 10); err != nil {
+		return err
+	}
+	if c.Loglevel != nil && !loglevel.Valid(*c.Loglevel) {
+		return fmt.Errorf("unknown loglevel [%s]", *c.Loglevel)
+	}
+	return nil
+}
+
+func checkRange(name string, v *int, min int, max int) error {
+	if v != nil && (*v < min || *v > max) {
+		return fmt.Errorf("%s must be between %d-%d [%d]", name, min, max, *v)
+	}
+	return nil
+}
+
+// params returns the set core settings as switch.conf params
+func (c coreSettings) params() []param {
+	p := []param{}
+	addInt := func(name string, v *int) {
+		if v != nil {
+			p = append(p, param{Name: name, Value: strconv.Itoa(*v)})
+		}
+	}
+	addBool := func(name string, v *bool) {
+		if v != nil {
+			p = append(p, param{Name: name, Value: strconv.FormatBool(*v)})
+		}
+	}
+	addString := func(name string, v *string) {
+		if v != nil {
+			p = append(p, param{Name: name, Value: *v})
+		}
+	}
+	addString("switchname", c.SwitchName)
+	addBool("colorize-console", c.Colorize)
+	addBool("dump-cores", c.DumpCores)
+	addString("loglevel", c.Loglevel)
+	addInt("debug-level", c.DebugLevel)
+	addInt("max-sessions", c.MaxSessions)
+	addInt("sessions-per-second", c.SessionsPerSecond)
+	addInt("min-idle-cpu", c.MinIdleCPU)
+	addInt("max-db-handles", c.MaxDBHandles)
+	addInt("db-handle-timeout", c.DBHandleTimeout)
+	addInt("rtp-start-port", c.RTPStartPort)
+	addInt("rtp-end-port", c.RTPEndPort)
+	addString("core-db-dsn", c.CoreDBDSN)
+	return p
+}
@@ -0,0 +1,115 @@
+package syslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/loglevel"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "syslog.json"
+	configTemplate = "configuration/syslog/syslog.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	facilities = map[string]bool{
+		"kern":     true,
+		"user":     true,
+		"mail":     true,
+		"daemon":   true,
+		"auth":     true,
+		"syslog":   true,
+		"lpr":      true,
+		"news":     true,
+		"uucp":     true,
+		"cron":     true,
+		"authpriv": true,
+		"ftp":      true,
+		"local0":   true,
+		"local1":   true,
+		"local2":   true,
+		"local3":   true,
+		"local4":   true,
+		"local5":   true,
+		"local6":   true,
+		"local7":   true,
+	}
+)
+
+type syslog struct {
+	Facility string `json:"facility"`
+	Ident    string `json:"ident"`
+	Loglevel string `json:"loglevel"`
+	UUID     bool   `json:"uuid"`
+}
+
+type module struct {
+	Syslog syslog `json:"syslog.conf"`
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_syslog")
+	rlog.Infof("set syslog module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set syslog template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	m := module{}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return err
+	}
+	if m.Syslog.Facility != "" && !facilities[m.Syslog.Facility] {
+		rlog.Errorf("unknown syslog facility for hostname [%s] [%s]", hostname, m.Syslog.Facility)
+		return fmt.Errorf("unknown syslog facility [%s]", m.Syslog.Facility)
+	}
+	if m.Syslog.Loglevel != "" && !loglevel.Valid(m.Syslog.Loglevel) {
+		rlog.Errorf("unknown log level for hostname [%s] [%s]", hostname, m.Syslog.Loglevel)
+		return fmt.Errorf("unknown log level [%s]", m.Syslog.Loglevel)
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Syslog)
+	return nil
+}
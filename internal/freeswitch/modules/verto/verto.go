@@ -0,0 +1,121 @@
+package verto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/acl"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile = "verto.json"
+	configTemplate = "configuration/verto/verto.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+)
+
+type settings struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type bindLocal struct {
+	Address string `json:"address"`
+	Secure  bool   `json:"secure"`
+}
+
+type vhost struct {
+	Domain   string     `json:"domain"`
+	Settings []settings `json:"settings"`
+}
+
+type profiles struct {
+	Name              string      `json:"name"`
+	BindLocal         []bindLocal `json:"bind_local"`
+	Userauth          bool        `json:"userauth"`
+	BlindReg          bool        `json:"blind_reg"`
+	MCastIP           string      `json:"mcast_ip"`
+	MCastPort         int         `json:"mcast_port"`
+	RTPIP             []string    `json:"rtp_ip"`
+	LocalNetwork      string      `json:"local_network"`
+	ApplyCandidateACL []string    `json:"apply_candidate_acl"`
+	Settings          []settings  `json:"settings"`
+	VHosts            []vhost     `json:"vhosts"`
+}
+
+type verto struct {
+	Settings []settings `json:"settings"`
+	Profiles []profiles `json:"profiles"`
+}
+
+type module struct {
+	Verto verto `json:"verto.conf"`
+}
+
+type host map[string]module
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, configTemplate)
+	modules.Uses(moduleDataFile, "mod_verto")
+	rlog.Infof("set verto module settings file [%s]", moduleSettingFile)
+	rlog.Infof("set verto template path [%s]", templatePath)
+	return nil
+}
+
+func Handler(ctx context.Context, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("configuration request for hostname [%s]", hostname)
+
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	for _, p := range m.Verto.Profiles {
+		if len(p.BindLocal) == 0 {
+			rlog.Errorf("profile has no bind-local [%s]", p.Name)
+			return errors.New("profile has no bind-local")
+		}
+		names := p.ApplyCandidateACL
+		if p.LocalNetwork != "" {
+			names = append([]string{p.LocalNetwork}, names...)
+		}
+		for _, n := range names {
+			ok, err := acl.Exists(hostname, n)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				rlog.Errorf("acl list not defined for hostname [%s] [%s] [%s]", hostname, p.Name, n)
+				return errors.New("acl list not defined")
+			}
+		}
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, m.Verto)
+	return nil
+}
@@ -0,0 +1,149 @@
+package chatplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile   = "chatplan.json"
+	chatplanTemplate = "chatplan/chatplan.xml"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	// message headers mod_sms conditions are matched against
+	fields = map[string]bool{
+		"to":        true,
+		"to_user":   true,
+		"to_host":   true,
+		"from":      true,
+		"from_user": true,
+		"from_host": true,
+		"proto":     true,
+		"type":      true,
+		"_body":     true,
+	}
+
+	// mod_sms chatplan applications
+	applications = map[string]bool{
+		"reply":  true,
+		"send":   true,
+		"lua":    true,
+		"set":    true,
+		"unset":  true,
+		"stop":   true,
+		"fire":   true,
+		"info":   true,
+		"system": true,
+	}
+)
+
+type action struct {
+	Application string `json:"application"`
+	Data        string `json:"data"`
+}
+
+type condition struct {
+	Field       string   `json:"field"`
+	Expression  string   `json:"expression"`
+	Actions     []action `json:"actions"`
+	AntiActions []action `json:"anti_actions"`
+}
+
+type extension struct {
+	Name       string      `json:"name"`
+	Continue   bool        `json:"continue"`
+	Conditions []condition `json:"conditions"`
+}
+
+type chatContext struct {
+	Name       string      `json:"name"`
+	Extensions []extension `json:"extensions"`
+}
+
+type module struct {
+	Contexts []chatContext `json:"chatplan"`
+}
+
+type host map[string]module
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, chatplanTemplate)
+	rlog.Infof("set chatplan settings file [%s]", moduleSettingFile)
+	rlog.Infof("set chatplan template path [%s]", templatePath)
+	return nil
+}
+
+// Handler answers a mod_sms chatplan lookup with the requested context
+func Handler(ctx context.Context, chatContextName string, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("chatplan request for hostname [%s] [%s]", hostname, chatContextName)
+
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	var c *chatContext
+	for i := range m.Contexts {
+		if m.Contexts[i].Name == chatContextName {
+			c = &m.Contexts[i]
+			break
+		}
+	}
+	if c == nil {
+		rlog.Infof("chatplan context not found [%s] [%s]", hostname, chatContextName)
+		return errors.New("context not found")
+	}
+	if err = validate(*c); err != nil {
+		rlog.Errorf("invalid chatplan for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, c)
+	return nil
+}
+
+func validate(c chatContext) error {
+	for _, e := range c.Extensions {
+		if e.Name == "" {
+			return fmt.Errorf("extension name not set [%s]", c.Name)
+		}
+		for _, cond := range e.Conditions {
+			if !fields[cond.Field] {
+				return fmt.Errorf("unsupported condition field [%s] [%s]", e.Name, cond.Field)
+			}
+			for _, a := range append(append([]action{}, cond.Actions...), cond.AntiActions...) {
+				if !applications[a.Application] {
+					return fmt.Errorf("unsupported chatplan application [%s] [%s]", e.Name, a.Application)
+				}
+			}
+		}
+	}
+	return nil
+}
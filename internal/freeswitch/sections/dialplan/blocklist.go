@@ -0,0 +1,205 @@
+package dialplan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultHangupCause = "CALL_REJECTED"
+
+	// names of the lists in the `blocked_by` channel variable
+	globalBlocklist = "global"
+	domainBlocklist = "domain"
+)
+
+var (
+	// hangup causes that make sense for a rejected caller
+	hangupCauses = map[string]bool{
+		"CALL_REJECTED":            true,
+		"USER_BUSY":                true,
+		"UNALLOCATED_NUMBER":       true,
+		"NO_ROUTE_DESTINATION":     true,
+		"NORMAL_CLEARING":          true,
+		"NO_ANSWER":                true,
+		"NO_USER_RESPONSE":         true,
+		"SERVICE_UNAVAILABLE":      true,
+		"FACILITY_REJECTED":        true,
+		"NORMAL_TEMPORARY_FAILURE": true,
+		"INCOMING_CALL_BARRED":     true,
+		"DESTINATION_OUT_OF_ORDER": true,
+	}
+
+	// caller id numbers carriers send when the caller withheld it
+	anonymousNumbers = map[string]bool{
+		"":            true,
+		"anonymous":   true,
+		"restricted":  true,
+		"private":     true,
+		"unavailable": true,
+		"unknown":     true,
+	}
+
+	validBlockPrefix = regexp.MustCompile(`^\+[0-9]+$`)
+)
+
+// Blocklists are checked for calls to a DID, the global list and the list of the DID's domain
+type Blocklists struct {
+	Global  Blocklist            `json:"global"`
+	Domains map[string]Blocklist `json:"domains,omitempty"`
+}
+
+// Blocklist rejects callers by E.164 number, number prefix or because they withheld their number
+type Blocklist struct {
+	Numbers   []string   `json:"numbers"`
+	Prefixes  []string   `json:"prefixes"`
+	Anonymous bool       `json:"anonymous"`
+	Treatment *Treatment `json:"treatment,omitempty"`
+}
+
+// Treatment is what a blocked caller gets, the announcement is played as early media before the
+// call is hung up with the cause
+type Treatment struct {
+	HangupCause  string `json:"hangup_cause"`
+	Announcement string `json:"announcement"`
+}
+
+// caller of a lookup, anonymous when the number is withheld or the caller asked for privacy
+type caller struct {
+	Number    string
+	Anonymous bool
+	E164      string
+}
+
+func (b Blocklist) validate() error {
+	for _, n := range b.Numbers {
+		if !validE164.MatchString(n) {
+			return fmt.Errorf("blocked number must be E.164 [%s]", n)
+		}
+	}
+	for _, p := range b.Prefixes {
+		if !validBlockPrefix.MatchString(p) {
+			return fmt.Errorf("blocked prefix must be + followed by digits [%s]", p)
+		}
+	}
+	if b.Treatment != nil && b.Treatment.HangupCause != "" && !hangupCauses[b.Treatment.HangupCause] {
+		return fmt.Errorf("unsupported hangup cause [%s]", b.Treatment.HangupCause)
+	}
+	return nil
+}
+
+func (b Blocklists) validate() error {
+	if err := b.Global.validate(); err != nil {
+		return fmt.Errorf("%s [%s]", err.Error(), globalBlocklist)
+	}
+	for d, l := range b.Domains {
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("%s [%s]", err.Error(), d)
+		}
+	}
+	return nil
+}
+
+// blocks reports whether the caller is on the list
+func (b Blocklist) blocks(c caller) bool {
+	if c.Anonymous && b.Anonymous {
+		return true
+	}
+	if c.E164 == "" {
+		return false
+	}
+	for _, n := range b.Numbers {
+		if n == c.E164 {
+			return true
+		}
+	}
+	for _, p := range b.Prefixes {
+		if strings.HasPrefix(c.E164, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// blocked returns the list blocking the caller of a call to `domain` and the treatment to apply.
+// The domain list is checked first, its treatment is used when set, otherwise the global one
+func (b Blocklists) blocked(domain string, c caller) (string, Treatment, bool) {
+	t := Treatment{HangupCause: defaultHangupCause}
+	if b.Global.Treatment != nil {
+		t = b.Global.Treatment.withDefaults()
+	}
+	if d, ok := b.Domains[domain]; ok && d.blocks(c) {
+		if d.Treatment != nil {
+			t = d.Treatment.withDefaults()
+		}
+		return domainBlocklist, t, true
+	}
+	if b.Global.blocks(c) {
+		return globalBlocklist, t, true
+	}
+	return "", t, false
+}
+
+func (t Treatment) withDefaults() Treatment {
+	if t.HangupCause == "" {
+		t.HangupCause = defaultHangupCause
+	}
+	return t
+}
+
+// newCaller works out who is calling. A caller asking for privacy still sends its number, so it is
+// also matched against the numbers and prefixes
+func newCaller(n numbering, number string, hidden bool) caller {
+	withheld := anonymousNumbers[strings.ToLower(strings.TrimSpace(number))]
+	c := caller{Number: number, Anonymous: hidden || withheld}
+	if !withheld {
+		c.E164, _ = n.normalize(number)
+	}
+	return c
+}
+
+// blockedExtension answers a call from a blocked caller. It is pinned to the caller so it can not
+// apply to anyone else should FreeSWITCH reuse it
+func blockedExtension(number string, dest condition, c caller, list string, t Treatment) extension {
+	cond := condition{
+		Field:      "caller_id_number",
+		Expression: "^" + regexp.QuoteMeta(c.Number) + "$",
+		Actions:    []action{{Application: "set", Data: "blocked_by=" + list}},
+	}
+	if t.Announcement != "" {
+		cond.Actions = append(cond.Actions,
+			action{Application: "pre_answer"},
+			action{Application: "playback", Data: t.Announcement},
+		)
+	}
+	cond.Actions = append(cond.Actions, action{Application: "hangup", Data: t.HangupCause})
+	return extension{
+		Name:       "blocked_" + strings.TrimPrefix(number, "+"),
+		Conditions: []condition{dest, cond},
+	}
+}
+
+// normalizeBlocklist brings the numbers to E.164 so lists can be edited with numbers as dialed
+func normalizeBlocklist(n numbering, b Blocklist) (Blocklist, error) {
+	numbers := make([]string, 0, len(b.Numbers))
+	seen := map[string]bool{}
+	for _, v := range b.Numbers {
+		e, err := n.normalize(v)
+		if err != nil {
+			return b, fmt.Errorf("%s [%s]", err.Error(), v)
+		}
+		if !seen[e] {
+			numbers = append(numbers, e)
+			seen[e] = true
+		}
+	}
+	b.Numbers = numbers
+	if b.Prefixes == nil {
+		b.Prefixes = []string{}
+	}
+	if err := b.validate(); err != nil {
+		return b, err
+	}
+	return b, nil
+}
@@ -0,0 +1,352 @@
+package dialplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/fifo"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile   = "dialplan.json"
+	dialplanTemplate = "dialplan/dialplan.xml"
+
+	// context inbound calls from carriers land in
+	PublicContext = "public"
+
+	defaultTransferContext   = "default"
+	defaultConferenceProfile = "default"
+)
+
+// DID destination types
+const (
+	DestinationExtension  = "extension"
+	DestinationQueue      = "queue"
+	DestinationConference = "conference"
+	DestinationIVR        = "ivr"
+	DestinationExternal   = "external"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	destinations = map[string]bool{
+		DestinationExtension:  true,
+		DestinationQueue:      true,
+		DestinationConference: true,
+		DestinationIVR:        true,
+		DestinationExternal:   true,
+	}
+)
+
+// destination a call is sent to, `context` is only used by extension destinations
+type destination struct {
+	Type    string `json:"type"`
+	Target  string `json:"target"`
+	Context string `json:"context,omitempty"`
+}
+
+// did routes an inbound number to a destination. The host entry scopes it to a FreeSWITCH host and
+// `domain` to a directory domain of that host. With a schedule, calls outside its hours go to `closed`
+type did struct {
+	destination
+	Number   string       `json:"number"`
+	Domain   string       `json:"domain"`
+	Schedule string       `json:"schedule,omitempty"`
+	Closed   *destination `json:"closed,omitempty"`
+}
+
+type module struct {
+	Numbering  numbering      `json:"numbering"`
+	Schedules  []Schedule     `json:"schedules"`
+	DIDs       []did          `json:"dids"`
+	Outbound   outboundRoutes `json:"outbound"`
+	Blocklists Blocklists     `json:"blocklists"`
+}
+
+// Request is a dialplan lookup as sent by mod_xml_curl
+type Request struct {
+	Hostname          string
+	Context           string
+	DestinationNumber string
+	CallerIDNumber    string
+	// caller asked for its number not to be shown
+	PrivacyHideNumber bool
+}
+
+type action struct {
+	Application string
+	Data        string
+	Inline      bool
+}
+
+// condition matches either a field against an expression or the time of the call
+type condition struct {
+	Field       string
+	Expression  string
+	Wday        string
+	TimeOfDay   string
+	DateTime    string
+	Actions     []action
+	AntiActions []action
+}
+
+type extension struct {
+	Name       string
+	Continue   bool
+	Conditions []condition
+}
+
+// document passed to the template
+type document struct {
+	Context    string
+	Extensions []extension
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, dialplanTemplate)
+	rlog.Infof("set dialplan settings file [%s]", moduleSettingFile)
+	rlog.Infof("set dialplan template path [%s]", templatePath)
+	return nil
+}
+
+// Handler answers a dialplan lookup. Calls in the public context are routed by their E.164
+// destination number through the DID table, calls in the outbound context through the outbound
+// routes
+func Handler(ctx context.Context, req Request, w http.ResponseWriter) error {
+	rlog.Debugf("dialplan request for hostname [%s] [%s] [%s]", req.Hostname, req.Context, req.DestinationNumber)
+
+	m, err := load(req.Hostname)
+	if err != nil {
+		return err
+	}
+	var doc document
+	switch {
+	case req.Context == PublicContext:
+		doc, err = inbound(req, m)
+	case m.Outbound.handles(req.Context):
+		doc, err = outbound(req, m)
+	default:
+		rlog.Infof("dialplan context not handled [%s] [%s]", req.Hostname, req.Context)
+		return errors.New("context not found")
+	}
+	if err != nil {
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, doc)
+	return nil
+}
+
+// inbound routes a call from a carrier through the DID table
+func inbound(req Request, m module) (document, error) {
+	doc := document{Context: PublicContext}
+	number, err := m.Numbering.normalize(req.DestinationNumber)
+	if err != nil {
+		rlog.Infof("could not normalize destination number [%s] [%s]", req.DestinationNumber, err.Error())
+		return doc, err
+	}
+	// the whole table is checked so a number listed twice is caught whichever entry would be used
+	var d *did
+	seen := map[string]bool{}
+	for i := range m.DIDs {
+		n, err := m.Numbering.normalize(m.DIDs[i].Number)
+		if err != nil {
+			rlog.Errorf("invalid did number for hostname [%s] [%s] [%s]", req.Hostname, m.DIDs[i].Number, err.Error())
+			return doc, err
+		}
+		if seen[n] {
+			rlog.Errorf("duplicate did for hostname [%s] [%s]", req.Hostname, n)
+			return doc, fmt.Errorf("duplicate did [%s]", n)
+		}
+		seen[n] = true
+		if n == number {
+			d = &m.DIDs[i]
+		}
+	}
+	if d == nil {
+		rlog.Infof("did not found [%s] [%s]", req.Hostname, number)
+		return doc, errors.New("did not found")
+	}
+	if err = validateDID(req.Hostname, m, *d); err != nil {
+		rlog.Errorf("invalid did for hostname [%s] [%s]", req.Hostname, err.Error())
+		return doc, err
+	}
+	name := "did_" + strings.TrimPrefix(number, "+")
+	dest := destinationCondition(req.DestinationNumber)
+	c := newCaller(m.Numbering, req.CallerIDNumber, req.PrivacyHideNumber)
+	if list, t, ok := m.Blocklists.blocked(d.Domain, c); ok {
+		rlog.Infof("caller blocked [%s] [%s] [%s] [%s]", req.Hostname, req.CallerIDNumber, number, list)
+		doc.Extensions = []extension{blockedExtension(number, dest, c, list, t)}
+		return doc, nil
+	}
+	final := dest
+	final.Actions = didActions(d.destination, d.Domain, number)
+	if d.Schedule == "" {
+		doc.Extensions = []extension{{Name: name, Conditions: []condition{final}}}
+		return doc, nil
+	}
+	sch, _ := findSchedule(m, d.Schedule)
+	doc.Extensions = scheduleExtensions(name, dest, sch)
+	final.Actions = nil
+	open := condition{
+		Field:       "${schedule_open}",
+		Expression:  "^true$",
+		Actions:     didActions(d.destination, d.Domain, number),
+		AntiActions: didActions(*d.Closed, d.Domain, number),
+	}
+	doc.Extensions = append(doc.Extensions, extension{Name: name, Conditions: []condition{final, open}})
+	return doc, nil
+}
+
+// destinationCondition matches the number exactly as it was dialed
+func destinationCondition(number string) condition {
+	return condition{
+		Field:      "destination_number",
+		Expression: "^" + regexp.QuoteMeta(number) + "$",
+	}
+}
+
+func didActions(d destination, domain string, number string) []action {
+	a := []action{
+		{Application: "set", Data: "e164_destination_number=" + number},
+		{Application: "set", Data: "domain_name=" + domain},
+	}
+	switch d.Type {
+	case DestinationExtension:
+		c := d.Context
+		if c == "" {
+			c = defaultTransferContext
+		}
+		a = append(a, action{Application: "transfer", Data: d.Target + " XML " + c})
+	case DestinationQueue:
+		a = append(a, action{Application: "answer"}, action{Application: "fifo", Data: d.Target + " in"})
+	case DestinationConference:
+		c := d.Target
+		if !strings.Contains(c, "@") {
+			c += "@" + defaultConferenceProfile
+		}
+		a = append(a, action{Application: "answer"}, action{Application: "conference", Data: c})
+	case DestinationIVR:
+		a = append(a, action{Application: "answer"}, action{Application: "ivr", Data: d.Target})
+	case DestinationExternal:
+		a = append(a, action{Application: "bridge", Data: d.Target})
+	}
+	return a
+}
+
+func findSchedule(m module, name string) (Schedule, bool) {
+	for _, s := range m.Schedules {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Schedule{}, false
+}
+
+// validateDID checks a did against the other module data of the host
+func validateDID(hostname string, m module, d did) error {
+	if d.Domain == "" {
+		return fmt.Errorf("domain not set [%s]", d.Number)
+	}
+	ok, err := directory.DomainExists(hostname, d.Domain)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("domain not in directory [%s] [%s]", d.Number, d.Domain)
+	}
+	if err = validateDestination(hostname, d.destination); err != nil {
+		return fmt.Errorf("%s [%s]", err.Error(), d.Number)
+	}
+	if d.Schedule == "" {
+		if d.Closed != nil {
+			return fmt.Errorf("closed destination without a schedule [%s]", d.Number)
+		}
+		return nil
+	}
+	if _, ok := findSchedule(m, d.Schedule); !ok {
+		return fmt.Errorf("schedule not found [%s] [%s]", d.Number, d.Schedule)
+	}
+	if d.Closed == nil {
+		return fmt.Errorf("schedule without a closed destination [%s]", d.Number)
+	}
+	if err = validateDestination(hostname, *d.Closed); err != nil {
+		return fmt.Errorf("%s [%s] closed", err.Error(), d.Number)
+	}
+	return nil
+}
+
+func validateDestination(hostname string, d destination) error {
+	if !destinations[d.Type] {
+		return fmt.Errorf("unknown destination type [%s]", d.Type)
+	}
+	if d.Target == "" {
+		return errors.New("target not set")
+	}
+	if d.Type == DestinationQueue {
+		ok, err := fifo.Exists(hostname, d.Target)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("fifo not found [%s]", d.Target)
+		}
+	}
+	return nil
+}
+
+func load(hostname string) (module, error) {
+	m := module{}
+	h, err := readEntries(hostname)
+	if err != nil {
+		return m, err
+	}
+	r, ok, err := inherit.Host(h, hostname)
+	if err != nil {
+		rlog.Errorf("could not resolve inherited settings [%s]", err.Error())
+		return m, err
+	}
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return m, errors.New("hostname not found")
+	}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		rlog.Errorf("could not unmarshal settings [%s]", err.Error())
+		return m, err
+	}
+	if err = m.Numbering.validate(); err != nil {
+		rlog.Errorf("invalid numbering for hostname [%s] [%s]", hostname, err.Error())
+		return m, err
+	}
+	if err = m.Blocklists.validate(); err != nil {
+		rlog.Errorf("invalid blocklists for hostname [%s] [%s]", hostname, err.Error())
+		return m, err
+	}
+	if err = m.Outbound.validateContext(); err != nil {
+		rlog.Errorf("invalid outbound routes for hostname [%s] [%s]", hostname, err.Error())
+		return m, err
+	}
+	if err = validateSchedules(m.Schedules); err != nil {
+		rlog.Errorf("invalid schedules for hostname [%s] [%s]", hostname, err.Error())
+		return m, err
+	}
+	return m, nil
+}
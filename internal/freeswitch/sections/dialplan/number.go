@@ -0,0 +1,80 @@
+package dialplan
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultInternationalPrefix = "00"
+)
+
+var (
+	validE164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+	// formatting people and carriers put in numbers
+	numberFormatting = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+)
+
+// numbering plan used to bring dialed numbers to E.164
+type numbering struct {
+	CountryCode          string `json:"country_code"`
+	InternationalPrefix  string `json:"international_prefix"`
+	NationalPrefix       string `json:"national_prefix"`
+	NationalNumberLength int    `json:"national_number_length"`
+}
+
+// normalize returns the E.164 form of a number. Numbers already starting with + or the
+// international prefix are taken as international, numbers with the national prefix or of national
+// length get the country code, as do numbers that are the country code followed by a national number
+func (n numbering) normalize(number string) (string, error) {
+	v := numberFormatting.Replace(strings.TrimSpace(number))
+	ip := n.InternationalPrefix
+	if ip == "" {
+		ip = defaultInternationalPrefix
+	}
+	switch {
+	case strings.HasPrefix(v, "+"):
+	case strings.HasPrefix(v, ip):
+		v = "+" + strings.TrimPrefix(v, ip)
+	case n.CountryCode == "":
+		return "", errors.New("no country code to normalize a national number")
+	case n.NationalPrefix != "" && strings.HasPrefix(v, n.NationalPrefix):
+		v = "+" + n.CountryCode + strings.TrimPrefix(v, n.NationalPrefix)
+	case n.NationalNumberLength > 0 && len(v) == n.NationalNumberLength:
+		v = "+" + n.CountryCode + v
+	case n.NationalNumberLength > 0 && len(v) == len(n.CountryCode)+n.NationalNumberLength && strings.HasPrefix(v, n.CountryCode):
+		v = "+" + v
+	default:
+		return "", errors.New("number is not in a known format")
+	}
+	if !validE164.MatchString(v) {
+		return "", errors.New("number is not valid E.164")
+	}
+	return v, nil
+}
+
+func (n numbering) validate() error {
+	for _, v := range []string{n.CountryCode, n.InternationalPrefix, n.NationalPrefix} {
+		if v != "" && !onlyDigits(v) {
+			return errors.New("country code and prefixes must be digits")
+		}
+	}
+	if n.CountryCode != "" && n.CountryCode[0] == '0' {
+		return errors.New("country code can not start with 0")
+	}
+	if n.NationalNumberLength < 0 {
+		return errors.New("national number length can not be negative")
+	}
+	return nil
+}
+
+func onlyDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package dialplan
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/sofia"
+)
+
+var (
+	validPrefix = regexp.MustCompile(`^\+[0-9]*$`)
+)
+
+// outboundRoutes are matched against the E.164 form of numbers dialed in `context`. The context has
+// to be named, a catch-all route in the context users dial extensions from would send out any number
+type outboundRoutes struct {
+	Context string  `json:"context"`
+	Routes  []route `json:"routes"`
+}
+
+// route sends numbers starting with `prefix` to its gateways, tried in order until one answers
+type route struct {
+	Prefix   string         `json:"prefix"`
+	Gateways []routeGateway `json:"gateways"`
+}
+
+// routeGateway is a sofia gateway with the digit rules the carrier expects, `strip` characters are
+// removed from the start of the E.164 number, plus included, and `prepend` is put in front
+type routeGateway struct {
+	Name    string `json:"name"`
+	Strip   int    `json:"strip"`
+	Prepend string `json:"prepend"`
+}
+
+// handles reports whether calls in context are routed by the outbound routes
+func (o outboundRoutes) handles(context string) bool {
+	return o.Context != "" && o.Context == context
+}
+
+// validateContext checks the context routes are used in, without it the routes are never used
+func (o outboundRoutes) validateContext() error {
+	if o.Context == "" && len(o.Routes) > 0 {
+		return errors.New("outbound context not set")
+	}
+	if o.Context == PublicContext {
+		return errors.New("outbound routes can not use the public context")
+	}
+	return nil
+}
+
+// validate checks the routes, every gateway has to be defined in the host's sofia.json
+func (o outboundRoutes) validate(hostname string) error {
+	if err := o.validateContext(); err != nil {
+		return err
+	}
+	gateways, err := sofia.Gateways(hostname)
+	if err != nil {
+		return err
+	}
+	prefixes := map[string]bool{}
+	for _, r := range o.Routes {
+		if !validPrefix.MatchString(r.Prefix) {
+			return fmt.Errorf("prefix must be + followed by digits [%s]", r.Prefix)
+		}
+		if prefixes[r.Prefix] {
+			return fmt.Errorf("duplicate prefix [%s]", r.Prefix)
+		}
+		prefixes[r.Prefix] = true
+		if len(r.Gateways) == 0 {
+			return fmt.Errorf("route without gateways [%s]", r.Prefix)
+		}
+		for _, g := range r.Gateways {
+			if g.Strip < 0 {
+				return fmt.Errorf("strip can not be negative [%s] [%s]", r.Prefix, g.Name)
+			}
+			if g.Prepend != "" && !onlyDigits(strings.TrimPrefix(g.Prepend, "+")) {
+				return fmt.Errorf("prepend must be digits [%s] [%s]", r.Prefix, g.Name)
+			}
+			if !gateways[g.Name] {
+				return fmt.Errorf("gateway not defined in sofia.json [%s] [%s]", r.Prefix, g.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// match returns the route with the longest prefix of number
+func (o outboundRoutes) match(number string) (route, bool) {
+	best := -1
+	for i, r := range o.Routes {
+		if strings.HasPrefix(number, r.Prefix) && (best < 0 || len(r.Prefix) > len(o.Routes[best].Prefix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return route{}, false
+	}
+	return o.Routes[best], true
+}
+
+// dialString returns the number as the gateway expects it
+func (g routeGateway) dialString(number string) (string, error) {
+	if g.Strip >= len(number) {
+		return "", fmt.Errorf("strip removes the whole number [%s] [%s]", g.Name, number)
+	}
+	return "sofia/gateway/" + g.Name + "/" + g.Prepend + number[g.Strip:], nil
+}
+
+// outbound routes a call from a user to the gateways of the longest matching prefix. The gateways
+// are bridged in order, a failed attempt moves on to the next one
+func outbound(req Request, m module) (document, error) {
+	doc := document{Context: m.Outbound.Context}
+	if err := m.Outbound.validate(req.Hostname); err != nil {
+		rlog.Errorf("invalid outbound routes for hostname [%s] [%s]", req.Hostname, err.Error())
+		return doc, err
+	}
+	number, err := m.Numbering.normalize(req.DestinationNumber)
+	if err != nil {
+		rlog.Infof("could not normalize destination number [%s] [%s]", req.DestinationNumber, err.Error())
+		return doc, err
+	}
+	r, ok := m.Outbound.match(number)
+	if !ok {
+		rlog.Infof("no outbound route [%s] [%s]", req.Hostname, number)
+		return doc, errors.New("route not found")
+	}
+	dials := make([]string, 0, len(r.Gateways))
+	for _, g := range r.Gateways {
+		d, err := g.dialString(number)
+		if err != nil {
+			rlog.Errorf("invalid outbound route for hostname [%s] [%s]", req.Hostname, err.Error())
+			return doc, err
+		}
+		dials = append(dials, d)
+	}
+	c := destinationCondition(req.DestinationNumber)
+	c.Actions = []action{
+		{Application: "set", Data: "e164_destination_number=" + number},
+		{Application: "set", Data: "outbound_route=" + r.Prefix},
+		{Application: "set", Data: "continue_on_fail=true"},
+		{Application: "set", Data: "hangup_after_bridge=true"},
+		{Application: "bridge", Data: strings.Join(dials, "|")},
+	}
+	doc.Extensions = []extension{{
+		Name:       "outbound_" + strings.TrimPrefix(number, "+"),
+		Conditions: []condition{c},
+	}}
+	return doc, nil
+}
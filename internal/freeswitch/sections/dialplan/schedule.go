@@ -0,0 +1,192 @@
+package dialplan
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04"
+)
+
+var (
+	// FreeSWITCH numbers week days from sunday
+	weekDays = map[string]int{
+		"sun": 1,
+		"mon": 2,
+		"tue": 3,
+		"wed": 4,
+		"thu": 5,
+		"fri": 6,
+		"sat": 7,
+	}
+
+	validTimeOfDay = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+	validName      = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Schedule is the opening hours of a destination. Calls outside the weekly hours or on a holiday
+// take the closed branch. Times are in the schedule's time zone
+type Schedule struct {
+	Name     string    `json:"name"`
+	Timezone string    `json:"timezone"`
+	Hours    []Hours   `json:"hours"`
+	Holidays []Holiday `json:"holidays"`
+}
+
+// Hours is an opening slot on some week days, days are `mon`..`sun` or ranges like `mon-fri`
+type Hours struct {
+	Days  []string `json:"days"`
+	Open  string   `json:"open"`
+	Close string   `json:"close"`
+}
+
+// Holiday closes a whole `date`, or the time between `start` and `end` for partial days
+type Holiday struct {
+	Name  string `json:"name"`
+	Date  string `json:"date,omitempty"`
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+func validateSchedules(schedules []Schedule) error {
+	names := map[string]bool{}
+	for _, s := range schedules {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate schedule [%s]", s.Name)
+		}
+		names[s.Name] = true
+	}
+	return nil
+}
+
+func (s Schedule) validate() error {
+	if !validName.MatchString(s.Name) {
+		return fmt.Errorf("invalid schedule name [%s]", s.Name)
+	}
+	if s.Timezone == "" {
+		return fmt.Errorf("timezone not set [%s]", s.Name)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone [%s] [%s]", s.Name, s.Timezone)
+	}
+	for _, h := range s.Hours {
+		if _, err := h.wday(); err != nil {
+			return fmt.Errorf("%s [%s]", err.Error(), s.Name)
+		}
+		if !validTimeOfDay.MatchString(h.Open) || !validTimeOfDay.MatchString(h.Close) {
+			return fmt.Errorf("open and close must be hh:mm [%s] [%s-%s]", s.Name, h.Open, h.Close)
+		}
+		// hours past midnight are given as a second slot on the next day
+		if h.Close <= h.Open {
+			return fmt.Errorf("close must be after open [%s] [%s-%s]", s.Name, h.Open, h.Close)
+		}
+	}
+	for _, h := range s.Holidays {
+		if _, err := h.dateTime(); err != nil {
+			return fmt.Errorf("%s [%s] [%s]", err.Error(), s.Name, h.Name)
+		}
+	}
+	return nil
+}
+
+// wday returns the days as a FreeSWITCH wday condition, like `2-6` or `1,7`
+func (h Hours) wday() (string, error) {
+	if len(h.Days) == 0 {
+		return "", errors.New("hours without days")
+	}
+	days := make([]string, 0, len(h.Days))
+	for _, d := range h.Days {
+		r := strings.SplitN(strings.ToLower(strings.TrimSpace(d)), "-", 2)
+		from, ok := weekDays[r[0]]
+		if !ok {
+			return "", fmt.Errorf("unknown day [%s]", d)
+		}
+		if len(r) == 1 {
+			days = append(days, strconv.Itoa(from))
+			continue
+		}
+		to, ok := weekDays[r[1]]
+		if !ok || to <= from {
+			return "", fmt.Errorf("invalid day range [%s]", d)
+		}
+		days = append(days, strconv.Itoa(from)+"-"+strconv.Itoa(to))
+	}
+	return strings.Join(days, ","), nil
+}
+
+// dateTime returns the holiday as a FreeSWITCH date-time condition
+func (h Holiday) dateTime() (string, error) {
+	if h.Date != "" {
+		if h.Start != "" || h.End != "" {
+			return "", errors.New("holiday has a date and a start or end")
+		}
+		d, err := time.Parse(dateLayout, h.Date)
+		if err != nil {
+			return "", errors.New("holiday date must be yyyy-mm-dd")
+		}
+		return d.Format(dateTimeLayout) + "~" + d.AddDate(0, 0, 1).Format(dateTimeLayout), nil
+	}
+	start, err := time.Parse(dateTimeLayout, h.Start)
+	if err != nil {
+		return "", errors.New("holiday start must be yyyy-mm-dd hh:mm")
+	}
+	end, err := time.Parse(dateTimeLayout, h.End)
+	if err != nil {
+		return "", errors.New("holiday end must be yyyy-mm-dd hh:mm")
+	}
+	if !end.After(start) {
+		return "", errors.New("holiday end must be after start")
+	}
+	return start.Format(dateTimeLayout) + "~" + end.Format(dateTimeLayout), nil
+}
+
+// scheduleExtensions returns the extensions that evaluate the schedule into the `schedule_open`
+// channel variable ahead of the extension branching on it. They are set inline so the final
+// extension sees the value while the dialplan is still being hunted
+func scheduleExtensions(name string, dest condition, s Schedule) []extension {
+	ext := []extension{{
+		Name:     name + "_schedule",
+		Continue: true,
+		Conditions: []condition{{
+			Field:      dest.Field,
+			Expression: dest.Expression,
+			Actions: []action{
+				{Application: "set", Data: "timezone=" + s.Timezone, Inline: true},
+				{Application: "set", Data: "schedule_open=false", Inline: true},
+			},
+		}},
+	}}
+	for i, h := range s.Hours {
+		wday, _ := h.wday()
+		ext = append(ext, extension{
+			Name:     fmt.Sprintf("%s_open_%d", name, i+1),
+			Continue: true,
+			Conditions: []condition{dest, {
+				Wday:      wday,
+				TimeOfDay: h.Open + "-" + h.Close,
+				Actions:   []action{{Application: "set", Data: "schedule_open=true", Inline: true}},
+			}},
+		})
+	}
+	for i, h := range s.Holidays {
+		dt, _ := h.dateTime()
+		ext = append(ext, extension{
+			Name:     fmt.Sprintf("%s_holiday_%d", name, i+1),
+			Continue: true,
+			Conditions: []condition{dest, {
+				DateTime: dt,
+				Actions:  []action{{Application: "set", Data: "schedule_open=false", Inline: true}},
+			}},
+		})
+	}
+	return ext
+}
@@ -0,0 +1,362 @@
+package dialplan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/inherit"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	schedulesKey  = "schedules"
+	blocklistsKey = "blocklists"
+)
+
+var (
+	// serializes changes to the dialplan module data files
+	mu sync.Mutex
+)
+
+// Schedules returns the schedules defined on an entry of the module data, a hostname or a group of
+// hosts. Inherited schedules are not included, they are edited on the entry defining them
+func Schedules(entry string) ([]Schedule, error) {
+	h, err := readEntries(entry)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeEntry(h, entry)
+	if err != nil {
+		return nil, err
+	}
+	return entrySchedules(e)
+}
+
+// SetSchedule adds the schedule to an entry of the module data or replaces the one with its name
+func SetSchedule(entry string, s Schedule) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, err := readEntries(entry)
+	if err != nil {
+		return err
+	}
+	e, err := decodeEntry(h, entry)
+	if err != nil {
+		return err
+	}
+	schedules, err := entrySchedules(e)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range schedules {
+		if schedules[i].Name == s.Name {
+			schedules[i] = s
+			replaced = true
+		}
+	}
+	if !replaced {
+		schedules = append(schedules, s)
+	}
+	if err = writeEntry(entry, e, schedulesKey, schedules); err != nil {
+		return err
+	}
+	rlog.Infof("set dialplan schedule [%s] [%s]", entry, s.Name)
+	return nil
+}
+
+// DeleteSchedule removes a schedule from an entry of the module data. A schedule still used by a
+// did of an entry that gets its schedules from this one is not removed
+func DeleteSchedule(entry string, name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the dids of every host are checked, including the hosts kept in their own files
+	h, err := moduledata.ReadAll(moduleSettingFile)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	for n := range h {
+		if scheduleSource(h, n) != entry {
+			continue
+		}
+		r, _, err := inherit.Resolve(h, n)
+		if err != nil {
+			return err
+		}
+		m := module{}
+		if err = moduledata.Unmarshal(r, &m); err != nil {
+			return err
+		}
+		for _, d := range m.DIDs {
+			if d.Schedule == name {
+				return fmt.Errorf("schedule in use [%s] [%s]", n, d.Number)
+			}
+		}
+	}
+	e, err := decodeEntry(h, entry)
+	if err != nil {
+		return err
+	}
+	schedules, err := entrySchedules(e)
+	if err != nil {
+		return err
+	}
+	kept := []Schedule{}
+	for _, s := range schedules {
+		if s.Name != name {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == len(schedules) {
+		return errors.New("schedule not found")
+	}
+	if err = writeEntry(entry, e, schedulesKey, kept); err != nil {
+		return err
+	}
+	rlog.Infof("deleted dialplan schedule [%s] [%s]", entry, name)
+	return nil
+}
+
+// scheduleSource returns the entry the schedules of `name` come from. Lists are not merged by
+// inheritance, so it is the nearest entry of the chain that has any
+func scheduleSource(h map[string]json.RawMessage, name string) string {
+	seen := map[string]bool{}
+	for name != "" && !seen[name] {
+		seen[name] = true
+		e := map[string]json.RawMessage{}
+		if err := moduledata.Unmarshal(h[name], &e); err != nil {
+			return ""
+		}
+		if _, ok := e[schedulesKey]; ok {
+			return name
+		}
+		parent := ""
+		if p, ok := e[inherit.Key]; ok {
+			moduledata.Unmarshal(p, &parent)
+		}
+		name = parent
+	}
+	return ""
+}
+
+// GetBlocklist returns the blocklist of a domain, or the global one when domain is empty, defined on
+// an entry of the module data
+func GetBlocklist(entry string, domain string) (Blocklist, error) {
+	h, err := readEntries(entry)
+	if err != nil {
+		return Blocklist{}, err
+	}
+	e, err := decodeEntry(h, entry)
+	if err != nil {
+		return Blocklist{}, err
+	}
+	b, err := entryBlocklists(e)
+	if err != nil {
+		return Blocklist{}, err
+	}
+	return b.list(domain), nil
+}
+
+// SetBlocklist replaces the blocklist of a domain, or the global one when domain is empty. Numbers
+// are stored in E.164, normalized with the numbering plan of the entry
+func SetBlocklist(entry string, domain string, l Blocklist) error {
+	return updateBlocklist(entry, domain, func(numbering, Blocklist) (Blocklist, error) {
+		return l, nil
+	})
+}
+
+// Block adds a number or a prefix to the blocklist of a domain, or the global one when domain is empty
+func Block(entry string, domain string, number string, prefix string) error {
+	return updateBlocklist(entry, domain, func(_ numbering, l Blocklist) (Blocklist, error) {
+		if number == "" && prefix == "" {
+			return l, errors.New("number or prefix required")
+		}
+		if number != "" {
+			l.Numbers = append(l.Numbers, number)
+		}
+		if prefix != "" && !contains(l.Prefixes, prefix) {
+			l.Prefixes = append(l.Prefixes, prefix)
+		}
+		return l, nil
+	})
+}
+
+// Unblock removes a number or a prefix from the blocklist of a domain, or the global one when domain
+// is empty
+func Unblock(entry string, domain string, number string, prefix string) error {
+	return updateBlocklist(entry, domain, func(nb numbering, l Blocklist) (Blocklist, error) {
+		n := len(l.Numbers) + len(l.Prefixes)
+		if number != "" {
+			e, err := nb.normalize(number)
+			if err != nil {
+				return l, fmt.Errorf("%s [%s]", err.Error(), number)
+			}
+			l.Numbers = remove(l.Numbers, e)
+		}
+		if prefix != "" {
+			l.Prefixes = remove(l.Prefixes, prefix)
+		}
+		if len(l.Numbers)+len(l.Prefixes) == n {
+			return l, errors.New("number or prefix not in blocklist")
+		}
+		return l, nil
+	})
+}
+
+// updateBlocklist applies a change to a blocklist, with the numbers normalized before and after so
+// numbers match however they were written
+func updateBlocklist(entry string, domain string, change func(numbering, Blocklist) (Blocklist, error)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, err := readEntries(entry)
+	if err != nil {
+		return err
+	}
+	r, ok, err := inherit.Resolve(h, entry)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("hostname not found")
+	}
+	m := module{}
+	if err = moduledata.Unmarshal(r, &m); err != nil {
+		return err
+	}
+	e, err := decodeEntry(h, entry)
+	if err != nil {
+		return err
+	}
+	b, err := entryBlocklists(e)
+	if err != nil {
+		return err
+	}
+	l, err := normalizeBlocklist(m.Numbering, b.list(domain))
+	if err != nil {
+		return err
+	}
+	if l, err = change(m.Numbering, l); err != nil {
+		return err
+	}
+	if l, err = normalizeBlocklist(m.Numbering, l); err != nil {
+		return err
+	}
+	if domain == "" {
+		b.Global = l
+	} else {
+		if b.Domains == nil {
+			b.Domains = map[string]Blocklist{}
+		}
+		b.Domains[domain] = l
+	}
+	if err = writeEntry(entry, e, blocklistsKey, b); err != nil {
+		return err
+	}
+	rlog.Infof("updated dialplan blocklist [%s] [%s] numbers [%d] prefixes [%d]", entry, domain, len(l.Numbers), len(l.Prefixes))
+	return nil
+}
+
+func entryBlocklists(e map[string]json.RawMessage) (Blocklists, error) {
+	b := Blocklists{}
+	if d, ok := e[blocklistsKey]; ok {
+		if err := moduledata.Unmarshal(d, &b); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+func (b Blocklists) list(domain string) Blocklist {
+	l := b.Global
+	if domain != "" {
+		l = b.Domains[domain]
+	}
+	if l.Numbers == nil {
+		l.Numbers = []string{}
+	}
+	if l.Prefixes == nil {
+		l.Prefixes = []string{}
+	}
+	return l
+}
+
+func contains(l []string, v string) bool {
+	for _, s := range l {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(l []string, v string) []string {
+	r := []string{}
+	for _, s := range l {
+		if s != v {
+			r = append(r, s)
+		}
+	}
+	return r
+}
+
+// readEntries returns the entries needed to resolve `entry`, the shared ones and its host file
+func readEntries(entry string) (map[string]json.RawMessage, error) {
+	h := map[string]json.RawMessage{}
+	d, err := moduledata.Read(moduleSettingFile, entry)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return h, err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return h, err
+	}
+	return h, nil
+}
+
+// decodeEntry splits an entry into its fields so the ones not being edited are written back as is
+func decodeEntry(h map[string]json.RawMessage, entry string) (map[string]json.RawMessage, error) {
+	d, ok := h[entry]
+	if !ok {
+		return nil, errors.New("hostname not found")
+	}
+	e := map[string]json.RawMessage{}
+	if err := moduledata.Unmarshal(d, &e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func entrySchedules(e map[string]json.RawMessage) ([]Schedule, error) {
+	schedules := []Schedule{}
+	if d, ok := e[schedulesKey]; ok {
+		if err := moduledata.Unmarshal(d, &schedules); err != nil {
+			return nil, err
+		}
+	}
+	return schedules, nil
+}
+
+// writeEntry sets the field `key` of an entry and writes it to the module data file holding it
+func writeEntry(entry string, e map[string]json.RawMessage, key string, v interface{}) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e[key] = d
+	if d, err = json.Marshal(e); err != nil {
+		return err
+	}
+	return moduledata.WriteEntry(moduleSettingFile, entry, d)
+}
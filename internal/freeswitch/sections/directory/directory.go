@@ -0,0 +1,307 @@
+package directory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/romana/rlog"
+)
+
+const (
+	moduleDataFile    = "directory.json"
+	directoryTemplate = "directory/directory.xml"
+)
+
+// directory lookup actions sent by FreeSWITCH
+const (
+	ActionSIPAuth           = "sip_auth"
+	ActionUserCall          = "user_call"
+	ActionGroupCall         = "group_call"
+	ActionMessageCount      = "message-count"
+	ActionReverseAuthLookup = "reverse-auth-lookup"
+
+	// purpose of the lookup sofia does when loading profiles
+	PurposeGateways = "gateways"
+)
+
+var (
+	moduleSettingFile string
+	templatePath      string
+
+	// user params sofia needs to authenticate a registration or call besides the credentials
+	authParams = map[string]bool{
+		"auth-acl":             true,
+		"allow-empty-password": true,
+		"sip-forbid-register":  true,
+	}
+
+	reverseAuthParams = map[string]bool{
+		"reverse-auth-user": true,
+		"reverse-auth-pass": true,
+	}
+)
+
+type param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type gateway struct {
+	Name   string  `json:"name"`
+	Params []param `json:"params,omitempty"`
+}
+
+// user credentials are kept as an a1-hash together with the realm it was computed for, a plaintext
+// password is only served when explicitly allowed
+type user struct {
+	ID        string    `json:"id"`
+	Password  string    `json:"password,omitempty"`
+	A1Hash    string    `json:"a1_hash,omitempty"`
+	A1Realm   string    `json:"a1_realm,omitempty"`
+	Params    []param   `json:"params,omitempty"`
+	Variables []param   `json:"variables,omitempty"`
+	Gateways  []gateway `json:"gateways,omitempty"`
+}
+
+// group params and variables are inherited by its members, the user's own values take precedence
+type group struct {
+	Name      string   `json:"name"`
+	Params    []param  `json:"params,omitempty"`
+	Variables []param  `json:"variables,omitempty"`
+	Users     []string `json:"users"`
+}
+
+type domain struct {
+	Name      string  `json:"name"`
+	Params    []param `json:"params,omitempty"`
+	Variables []param `json:"variables,omitempty"`
+	Groups    []group `json:"groups,omitempty"`
+	Users     []user  `json:"users,omitempty"`
+}
+
+type module struct {
+	Domains []domain `json:"directory"`
+}
+
+type host map[string]module
+
+// Request is a directory lookup as sent by mod_xml_curl
+type Request struct {
+	Hostname string
+	Domain   string
+	User     string
+	Group    string
+	Action   string
+	Purpose  string
+}
+
+// document passed to the template, only the parts the action needs are set
+type document struct {
+	Domain    string
+	Params    []param
+	Variables []param
+	Groups    []documentGroup
+	Users     []documentUser
+}
+
+type documentGroup struct {
+	Name  string
+	Users []string
+}
+
+type documentUser struct {
+	ID        string
+	Params    []param
+	Variables []param
+	Gateways  []gateway
+}
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	templatePath = filepath.Join(t, directoryTemplate)
+	rlog.Infof("set directory settings file [%s]", moduleSettingFile)
+	rlog.Infof("set directory template path [%s]", templatePath)
+	return nil
+}
+
+// Handler answers a directory lookup with the smallest document that satisfies its action
+func Handler(ctx context.Context, req Request, w http.ResponseWriter) error {
+	rlog.Debugf("directory request for hostname [%s] [%s] [%s@%s] [%s]", req.Hostname, req.Action, req.User, req.Domain, req.Purpose)
+
+	h, err := readHosts(req.Hostname)
+	if err != nil {
+		return err
+	}
+	m, ok := h[req.Hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", req.Hostname)
+		return errors.New("hostname not found")
+	}
+	var dom *domain
+	for i := range m.Domains {
+		if m.Domains[i].Name == req.Domain {
+			dom = &m.Domains[i]
+			break
+		}
+	}
+	if dom == nil {
+		rlog.Infof("domain not found [%s] [%s]", req.Hostname, req.Domain)
+		return errors.New("domain not found")
+	}
+	doc, err := build(*dom, req)
+	if err != nil {
+		rlog.Infof("could not answer directory request [%s] [%s]", req.Action, err.Error())
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, doc)
+	return nil
+}
+
+func build(d domain, req Request) (document, error) {
+	doc := document{Domain: d.Name}
+
+	if req.Purpose == PurposeGateways {
+		for _, u := range d.Users {
+			if len(u.Gateways) > 0 {
+				doc.Users = append(doc.Users, documentUser{ID: u.ID, Gateways: u.Gateways})
+			}
+		}
+		return doc, nil
+	}
+
+	if req.Action == ActionGroupCall {
+		for _, g := range d.Groups {
+			if g.Name == req.Group {
+				doc.Params = filter(d.Params, func(n string) bool { return n == "dial-string" })
+				doc.Groups = []documentGroup{{Name: g.Name, Users: g.Users}}
+				return doc, nil
+			}
+		}
+		return doc, errors.New("group not found")
+	}
+
+	u, ok := findUser(d, req.User)
+	if !ok {
+		return doc, errors.New("user not found")
+	}
+	u = inheritGroups(d, u)
+	du := documentUser{ID: u.ID}
+	switch req.Action {
+	case ActionSIPAuth:
+		du.Params = credentials(u, d.Name)
+		if len(du.Params) == 0 {
+			return doc, errors.New("user has no credentials")
+		}
+		du.Params = append(du.Params, filter(u.Params, func(n string) bool { return authParams[n] })...)
+		du.Variables = u.Variables
+		doc.Variables = d.Variables
+	case ActionUserCall:
+		du.Params = filter(u.Params, func(n string) bool { return n == "dial-string" })
+		if len(du.Params) == 0 {
+			doc.Params = filter(d.Params, func(n string) bool { return n == "dial-string" })
+		}
+		du.Variables = u.Variables
+		doc.Variables = d.Variables
+	case ActionMessageCount:
+		du.Params = filter(u.Params, func(n string) bool { return strings.HasPrefix(n, "vm-") || n == "mailbox" })
+	case ActionReverseAuthLookup:
+		du.Params = filter(u.Params, func(n string) bool { return reverseAuthParams[n] })
+		if len(du.Params) == 0 {
+			return doc, errors.New("user has no reverse auth credentials")
+		}
+	default:
+		// lookups without a known action, like user_exists or voicemail, get the whole user
+		du.Params = append(credentials(u, d.Name), u.Params...)
+		du.Variables = u.Variables
+		du.Gateways = u.Gateways
+		doc.Params = d.Params
+		doc.Variables = d.Variables
+	}
+	doc.Users = []documentUser{du}
+	return doc, nil
+}
+
+func findUser(d domain, id string) (user, bool) {
+	for _, u := range d.Users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return user{}, false
+}
+
+// inheritGroups returns the user with the params and variables of its groups merged in. Groups are
+// applied in the order the domain lists them and the user's own values override them. Domain values
+// are left out, FreeSWITCH applies those itself from the domain element
+func inheritGroups(d domain, u user) user {
+	params := []param{}
+	variables := []param{}
+	for _, g := range memberOf(d, u.ID) {
+		params = merge(params, g.Params)
+		variables = merge(variables, g.Variables)
+	}
+	u.Params = merge(params, u.Params)
+	u.Variables = merge(variables, u.Variables)
+	return u
+}
+
+func memberOf(d domain, id string) []group {
+	groups := []group{}
+	for _, g := range d.Groups {
+		for _, m := range g.Users {
+			if m == id {
+				groups = append(groups, g)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// merge returns a copy of base with the values of override set on top
+func merge(base []param, override []param) []param {
+	r := append([]param{}, base...)
+	for _, p := range override {
+		r = setParam(r, p)
+	}
+	return r
+}
+
+// credentials returns the password params. An a1-hash computed for another realm, left behind by a
+// domain rename, would never match so it is not served
+func credentials(u user, realm string) []param {
+	if u.A1Hash != "" {
+		if u.A1Realm != realm {
+			rlog.Warnf("a1-hash realm does not match domain, the password has to be set again [%s@%s] [%s]", u.ID, realm, u.A1Realm)
+			return nil
+		}
+		return []param{{Name: "a1-hash", Value: u.A1Hash}}
+	}
+	if u.Password != "" {
+		if !allowPlaintextPasswords {
+			rlog.Warnf("plaintext password rejected [%s@%s]", u.ID, realm)
+			return nil
+		}
+		return []param{{Name: "password", Value: u.Password}}
+	}
+	return nil
+}
+
+func filter(p []param, keep func(string) bool) []param {
+	r := []param{}
+	for _, v := range p {
+		if keep(v.Name) {
+			r = append(r, v)
+		}
+	}
+	return r
+}
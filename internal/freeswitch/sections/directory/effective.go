@@ -0,0 +1,75 @@
+package directory
+
+import (
+	"errors"
+)
+
+// sources of an effective value
+const (
+	SourceDomain = "domain"
+	SourceUser   = "user"
+	// group sources are named group:<name>
+	sourceGroupPrefix = "group:"
+)
+
+// EffectiveValue is a param or variable a user ends up with and where it was set
+type EffectiveValue struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// EffectiveUser is the view of a user after domain and group inheritance. Credentials are left out
+type EffectiveUser struct {
+	ID        string           `json:"id"`
+	Domain    string           `json:"domain"`
+	Groups    []string         `json:"groups"`
+	Params    []EffectiveValue `json:"params"`
+	Variables []EffectiveValue `json:"variables"`
+}
+
+// Effective returns the params and variables a user ends up with, resolving the domain values, then
+// the groups in the order the domain lists them and finally the user's own values
+func Effective(hostname string, domainName string, userID string) (EffectiveUser, error) {
+	e := EffectiveUser{ID: userID, Domain: domainName, Groups: []string{}, Params: []EffectiveValue{}, Variables: []EffectiveValue{}}
+	h, err := readHosts(hostname)
+	if err != nil {
+		return e, err
+	}
+	d, err := findDomain(h, hostname, domainName)
+	if err != nil {
+		return e, err
+	}
+	u, ok := findUser(*d, userID)
+	if !ok {
+		return e, errors.New("user not found")
+	}
+	e.Params = effective(e.Params, d.Params, SourceDomain)
+	e.Variables = effective(e.Variables, d.Variables, SourceDomain)
+	for _, g := range memberOf(*d, u.ID) {
+		e.Groups = append(e.Groups, g.Name)
+		e.Params = effective(e.Params, g.Params, sourceGroupPrefix+g.Name)
+		e.Variables = effective(e.Variables, g.Variables, sourceGroupPrefix+g.Name)
+	}
+	e.Params = effective(e.Params, u.Params, SourceUser)
+	e.Variables = effective(e.Variables, u.Variables, SourceUser)
+	return e, nil
+}
+
+func effective(values []EffectiveValue, params []param, source string) []EffectiveValue {
+	for _, p := range params {
+		found := false
+		for i := range values {
+			if values[i].Name == p.Name {
+				values[i].Value = p.Value
+				values[i].Source = source
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, EffectiveValue{Name: p.Name, Value: p.Value, Source: source})
+		}
+	}
+	return values
+}
@@ -0,0 +1,289 @@
+package directory
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/romana/rlog"
+)
+
+// columns understood by Import, any other column is set as a user variable named after the header
+const (
+	columnExtension      = "extension"
+	columnDomain         = "domain"
+	columnPassword       = "password"
+	columnCallerIDName   = "caller_id_name"
+	columnCallerIDNumber = "caller_id_number"
+	columnVoicemailPIN   = "voicemail_pin"
+	columnGroup          = "group"
+)
+
+var (
+	validExtension      = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+	validCallerIDNumber = regexp.MustCompile(`^\+?[0-9]+$`)
+	validVoicemailPIN   = regexp.MustCompile(`^[0-9]{4,}$`)
+	validVariableName   = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// ImportResult reports what an import changed, or the rows that kept it from being applied
+type ImportResult struct {
+	Created int        `json:"created"`
+	Updated int        `json:"updated"`
+	Errors  []RowError `json:"errors,omitempty"`
+}
+
+// RowError is a validation error for a csv row, rows are numbered from 1 with the header as row 1
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type importRow struct {
+	row       int
+	extension string
+	domain    string
+	password  string
+	params    []param
+	variables []param
+	groups    []string
+}
+
+// Import upserts the users in the csv into the directory of hostname. Every row is validated first
+// and nothing is written unless all rows are valid. Passwords are stored as an a1-hash, an empty
+// password keeps the existing credentials of a user and is an error for a new one
+func Import(hostname string, r io.Reader) (ImportResult, error) {
+	res := ImportResult{}
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return res, fmt.Errorf("could not read header [%s]", err.Error())
+	}
+	columns, err := importColumns(header)
+	if err != nil {
+		return res, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, err := readHosts(hostname)
+	if err != nil {
+		return res, err
+	}
+	if _, ok := h[hostname]; !ok {
+		return res, errors.New("hostname not found")
+	}
+
+	rows := []importRow{}
+	seen := map[string]int{}
+	for n := 2; ; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return res, err
+			}
+			res.Errors = append(res.Errors, RowError{Row: n, Error: err.Error()})
+			continue
+		}
+		if len(record) != len(columns) {
+			res.Errors = append(res.Errors, RowError{Row: n, Error: fmt.Sprintf("expected %d fields, got %d", len(columns), len(record))})
+			continue
+		}
+		row, err := parseRow(n, columns, record)
+		if err == nil {
+			err = validateRow(h, hostname, row)
+		}
+		if err == nil {
+			key := row.extension + "@" + row.domain
+			if first, ok := seen[key]; ok {
+				err = fmt.Errorf("duplicate of row %d", first)
+			} else {
+				seen[key] = n
+			}
+		}
+		if err != nil {
+			res.Errors = append(res.Errors, RowError{Row: n, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(res.Errors) > 0 {
+		rlog.Warnf("directory import rejected [%s] [%d] invalid rows", hostname, len(res.Errors))
+		return res, nil
+	}
+
+	for _, row := range rows {
+		d, _ := findDomain(h, hostname, row.domain)
+		if upsertUser(d, row) {
+			res.Created++
+		} else {
+			res.Updated++
+		}
+	}
+	if err = writeHost(h, hostname); err != nil {
+		return ImportResult{}, err
+	}
+	rlog.Infof("directory import [%s] created [%d] updated [%d] users", hostname, res.Created, res.Updated)
+	return res, nil
+}
+
+func importColumns(header []string) ([]string, error) {
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, c := range header {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" {
+			return nil, fmt.Errorf("empty column name in header, column %d", i+1)
+		}
+		if !validVariableName.MatchString(c) {
+			return nil, fmt.Errorf("invalid column name [%s]", c)
+		}
+		if seen[c] {
+			return nil, fmt.Errorf("duplicate column [%s]", c)
+		}
+		seen[c] = true
+		columns[i] = c
+	}
+	if !seen[columnExtension] || !seen[columnDomain] {
+		return nil, errors.New("header must have extension and domain columns")
+	}
+	return columns, nil
+}
+
+func parseRow(n int, columns []string, record []string) (importRow, error) {
+	row := importRow{row: n}
+	for i, c := range columns {
+		v := strings.TrimSpace(record[i])
+		switch c {
+		case columnExtension:
+			row.extension = v
+		case columnDomain:
+			row.domain = v
+		case columnPassword:
+			// passwords are taken as is, surrounding spaces may be intended
+			row.password = record[i]
+		case columnCallerIDName:
+			if v != "" {
+				row.variables = append(row.variables, param{Name: "effective_caller_id_name", Value: v})
+			}
+		case columnCallerIDNumber:
+			if v == "" {
+				continue
+			}
+			if !validCallerIDNumber.MatchString(v) {
+				return row, fmt.Errorf("invalid caller id number [%s]", v)
+			}
+			row.variables = append(row.variables, param{Name: "effective_caller_id_number", Value: v})
+		case columnVoicemailPIN:
+			if v == "" {
+				continue
+			}
+			if !validVoicemailPIN.MatchString(v) {
+				return row, errors.New("voicemail pin must be at least 4 digits")
+			}
+			row.params = append(row.params, param{Name: "vm-password", Value: v})
+		case columnGroup:
+			for _, g := range strings.Split(v, ";") {
+				if g = strings.TrimSpace(g); g != "" {
+					row.groups = append(row.groups, g)
+				}
+			}
+		default:
+			if v != "" {
+				row.variables = append(row.variables, param{Name: c, Value: v})
+			}
+		}
+	}
+	if row.extension == "" {
+		return row, errors.New("extension is required")
+	}
+	if !validExtension.MatchString(row.extension) {
+		return row, fmt.Errorf("invalid extension [%s]", row.extension)
+	}
+	if row.domain == "" {
+		return row, errors.New("domain is required")
+	}
+	return row, nil
+}
+
+func validateRow(h host, hostname string, row importRow) error {
+	d, err := findDomain(h, hostname, row.domain)
+	if err != nil {
+		return fmt.Errorf("domain not found [%s]", row.domain)
+	}
+	if row.password != "" {
+		return nil
+	}
+	for _, u := range d.Users {
+		if u.ID == row.extension {
+			return nil
+		}
+	}
+	return errors.New("password is required for a new user")
+}
+
+// upsertUser adds or updates the user of the row, it reports whether the user was created
+func upsertUser(d *domain, row importRow) bool {
+	var u *user
+	created := false
+	for i := range d.Users {
+		if d.Users[i].ID == row.extension {
+			u = &d.Users[i]
+			break
+		}
+	}
+	if u == nil {
+		d.Users = append(d.Users, user{ID: row.extension})
+		u = &d.Users[len(d.Users)-1]
+		created = true
+	}
+	if row.password != "" {
+		setHash(u, d.Name, row.password)
+	}
+	for _, p := range row.params {
+		u.Params = setParam(u.Params, p)
+	}
+	for _, v := range row.variables {
+		u.Variables = setParam(u.Variables, v)
+	}
+	for _, g := range row.groups {
+		addToGroup(d, g, u.ID)
+	}
+	return created
+}
+
+// setParam replaces the value of a param with the same name or appends it
+func setParam(params []param, p param) []param {
+	for i := range params {
+		if params[i].Name == p.Name {
+			params[i].Value = p.Value
+			return params
+		}
+	}
+	return append(params, p)
+}
+
+func addToGroup(d *domain, name string, userID string) {
+	for i := range d.Groups {
+		if d.Groups[i].Name != name {
+			continue
+		}
+		for _, id := range d.Groups[i].Users {
+			if id == userID {
+				return
+			}
+		}
+		d.Groups[i].Users = append(d.Groups[i].Users, userID)
+		return
+	}
+	d.Groups = append(d.Groups, group{Name: name, Users: []string{userID}})
+}
@@ -0,0 +1,152 @@
+package directory
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+var (
+	// serializes changes to the directory module data files
+	mu sync.Mutex
+
+	allowPlaintextPasswords bool
+)
+
+// AllowPlaintextPasswords sets whether users with a plaintext `password` are served. When disabled,
+// which is the default, only users with an a1-hash can authenticate
+func AllowPlaintextPasswords(allow bool) {
+	allowPlaintextPasswords = allow
+	rlog.Infof("set directory plaintext passwords allowed [%t]", allowPlaintextPasswords)
+}
+
+// A1Hash returns the digest authentication hash FreeSWITCH accepts in place of a password
+func A1Hash(user string, realm string, password string) string {
+	h := md5.Sum([]byte(user + ":" + realm + ":" + password))
+	return hex.EncodeToString(h[:])
+}
+
+// SetPassword stores the a1-hash of `password` for the user, using the domain name as the realm.
+// The plaintext password is never written
+func SetPassword(hostname string, domainName string, userID string, password string) error {
+	if password == "" {
+		return errors.New("password can not be empty")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, err := readHosts(hostname)
+	if err != nil {
+		return err
+	}
+	d, err := findDomain(h, hostname, domainName)
+	if err != nil {
+		return err
+	}
+	for i := range d.Users {
+		if d.Users[i].ID == userID {
+			setHash(&d.Users[i], domainName, password)
+			rlog.Infof("set password for user [%s] [%s@%s]", hostname, userID, domainName)
+			return writeHost(h, hostname)
+		}
+	}
+	return errors.New("user not found")
+}
+
+// RenameDomain renames a domain. An a1-hash is bound to the realm, so the hashes of users whose
+// password is given in `passwords` are recomputed for the new name, the others are returned and
+// can not authenticate until their password is set again
+func RenameDomain(hostname string, domainName string, newName string, passwords map[string]string) ([]string, error) {
+	if newName == "" {
+		return nil, errors.New("new domain name can not be empty")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	h, err := readHosts(hostname)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = findDomain(h, hostname, newName); err == nil {
+		return nil, errors.New("domain already exists")
+	}
+	d, err := findDomain(h, hostname, domainName)
+	if err != nil {
+		return nil, err
+	}
+	d.Name = newName
+	stale := []string{}
+	for i := range d.Users {
+		u := &d.Users[i]
+		if p, ok := passwords[u.ID]; ok && p != "" {
+			setHash(u, newName, p)
+			continue
+		}
+		if u.A1Hash != "" {
+			stale = append(stale, u.ID)
+		}
+	}
+	if err = writeHost(h, hostname); err != nil {
+		return nil, err
+	}
+	rlog.Infof("renamed domain [%s] [%s] to [%s], [%d] users need a new password", hostname, domainName, newName, len(stale))
+	return stale, nil
+}
+
+func setHash(u *user, realm string, password string) {
+	u.A1Hash = A1Hash(u.ID, realm, password)
+	u.A1Realm = realm
+	u.Password = ""
+}
+
+// DomainExists reports whether the directory of `hostname` has the domain `name`
+func DomainExists(hostname string, name string) (bool, error) {
+	h, err := readHosts(hostname)
+	if err != nil {
+		return false, err
+	}
+	_, err = findDomain(h, hostname, name)
+	return err == nil, nil
+}
+
+func findDomain(h host, hostname string, domainName string) (*domain, error) {
+	m, ok := h[hostname]
+	if !ok {
+		return nil, errors.New("hostname not found")
+	}
+	for i := range m.Domains {
+		if m.Domains[i].Name == domainName {
+			return &m.Domains[i], nil
+		}
+	}
+	return nil, errors.New("domain not found")
+}
+
+func readHosts(hostname string) (host, error) {
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return h, err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return h, err
+	}
+	return h, nil
+}
+
+// writeHost writes the directory of one host, to its host file when it has one
+func writeHost(h host, hostname string) error {
+	d, err := json.Marshal(h[hostname])
+	if err != nil {
+		return err
+	}
+	return moduledata.WriteEntry(moduleSettingFile, hostname, d)
+}
@@ -0,0 +1,149 @@
+package phrases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"text/template"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+const (
+	moduleDataFile    = "phrases.json"
+	phrasesTemplate   = "phrases/phrases.xml"
+	languagesTemplate = "phrases/languages.xml"
+)
+
+var (
+	moduleSettingFile     string
+	phrasesTemplatePath   string
+	languagesTemplatePath string
+)
+
+type action struct {
+	Function string `json:"function"`
+	Data     string `json:"data"`
+}
+
+type input struct {
+	Pattern      string   `json:"pattern"`
+	BreakOnMatch bool     `json:"break_on_match"`
+	Match        []action `json:"match"`
+	Nomatch      []action `json:"nomatch"`
+}
+
+type macro struct {
+	Name   string  `json:"name"`
+	Inputs []input `json:"inputs"`
+}
+
+type language struct {
+	Name        string  `json:"name"`
+	SayModule   string  `json:"say_module"`
+	SoundPrefix string  `json:"sound_prefix"`
+	TTSEngine   string  `json:"tts_engine"`
+	TTSVoice    string  `json:"tts_voice"`
+	Macros      []macro `json:"macros"`
+}
+
+type module struct {
+	Languages []language `json:"languages"`
+}
+
+type host map[string]module
+
+func New(m string, t string) error {
+	moduleSettingFile = filepath.Join(m, moduleDataFile)
+	phrasesTemplatePath = filepath.Join(t, phrasesTemplate)
+	languagesTemplatePath = filepath.Join(t, languagesTemplate)
+	rlog.Infof("set phrases settings file [%s]", moduleSettingFile)
+	rlog.Infof("set phrases template path [%s]", phrasesTemplatePath)
+	rlog.Infof("set languages template path [%s]", languagesTemplatePath)
+	return nil
+}
+
+// PhrasesHandler answers a `phrases` section lookup. FreeSWITCH takes the <language> element itself
+// as the macros of the phrases section, so the macros are its direct children
+func PhrasesHandler(ctx context.Context, lang string, hostname string, w http.ResponseWriter) error {
+	return handler(phrasesTemplatePath, lang, hostname, w)
+}
+
+// LanguagesHandler answers a `languages` section lookup, which FreeSWITCH tries before falling back to
+// the phrases section
+func LanguagesHandler(ctx context.Context, lang string, hostname string, w http.ResponseWriter) error {
+	return handler(languagesTemplatePath, lang, hostname, w)
+}
+
+func handler(templatePath string, lang string, hostname string, w http.ResponseWriter) error {
+	rlog.Debugf("language request for hostname [%s] [%s]", hostname, lang)
+
+	h := host{}
+	d, err := moduledata.Read(moduleSettingFile, hostname)
+	if err != nil {
+		rlog.Errorf("could not read file [%s]", err.Error())
+		return err
+	}
+	if err = moduledata.Unmarshal(d, &h); err != nil {
+		rlog.Errorf("could not unmarshal file [%s]", err.Error())
+		return err
+	}
+	m, ok := h[hostname]
+	if !ok {
+		rlog.Infof("hostname not found [%s]", hostname)
+		return errors.New("hostname not found")
+	}
+	var l *language
+	for i := range m.Languages {
+		if m.Languages[i].Name == lang {
+			l = &m.Languages[i]
+			break
+		}
+	}
+	if l == nil {
+		rlog.Infof("language not found [%s] [%s]", hostname, lang)
+		return errors.New("language not found")
+	}
+	if err = validate(*l); err != nil {
+		rlog.Errorf("invalid language for hostname [%s] [%s]", hostname, err.Error())
+		return err
+	}
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		rlog.Errorf("could not parse template file [%s]", err.Error())
+		return err
+	}
+	t.Execute(w, l)
+	return nil
+}
+
+func validate(l language) error {
+	if l.SayModule == "" {
+		return fmt.Errorf("say module not set [%s]", l.Name)
+	}
+	names := map[string]bool{}
+	for _, m := range l.Macros {
+		if m.Name == "" {
+			return fmt.Errorf("macro name not set [%s]", l.Name)
+		}
+		if names[m.Name] {
+			return fmt.Errorf("duplicate macro [%s] [%s]", l.Name, m.Name)
+		}
+		names[m.Name] = true
+		for _, i := range m.Inputs {
+			if i.Pattern == "" {
+				return fmt.Errorf("input pattern not set [%s] [%s]", l.Name, m.Name)
+			}
+			for _, a := range append(append([]action{}, i.Match...), i.Nomatch...) {
+				if a.Function == "" {
+					return fmt.Errorf("action function not set [%s] [%s]", l.Name, m.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
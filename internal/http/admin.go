@@ -0,0 +1,259 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/admin"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/dialplan"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+)
+
+const (
+	// largest admin request body accepted
+	maxAdminRequestSize = 1 << 20
+	// largest directory import accepted, enough for tens of thousands of users
+	maxImportRequestSize = 32 << 20
+)
+
+var (
+	admins adminHandler
+)
+
+type adminHandler struct{}
+
+type setPasswordRequest struct {
+	Hostname string `json:"hostname"`
+	Domain   string `json:"domain"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+type renameDomainRequest struct {
+	Hostname  string            `json:"hostname"`
+	Domain    string            `json:"domain"`
+	NewDomain string            `json:"new_domain"`
+	Passwords map[string]string `json:"passwords"`
+}
+
+// number or prefix added to or removed from a blocklist
+type blocklistEntryRequest struct {
+	Number string `json:"number"`
+	Prefix string `json:"prefix"`
+}
+
+type renameDomainResponse struct {
+	// users whose a1-hash could not be recomputed and need their password set again
+	Stale []string `json:"stale"`
+}
+
+// SetPassword stores the a1-hash for a directory user
+func (adminHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
+	req := setPasswordRequest{}
+	if !admins.decode(w, r, &req) {
+		return
+	}
+	if err := directory.SetPassword(req.Hostname, req.Domain, req.User, req.Password); err != nil {
+		rlog.Errorf("could not set password [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// RenameDomain renames a directory domain, recomputing the a1-hash of the users whose password is given
+func (adminHandler) RenameDomain(w http.ResponseWriter, r *http.Request) {
+	req := renameDomainRequest{}
+	if !admins.decode(w, r, &req) {
+		return
+	}
+	stale, err := directory.RenameDomain(req.Hostname, req.Domain, req.NewDomain, req.Passwords)
+	if err != nil {
+		rlog.Errorf("could not rename domain [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(renameDomainResponse{Stale: stale})
+	return
+}
+
+// Import upserts directory users from a csv body, the hostname is taken from the query string.
+// Nothing is imported when a row is invalid and the row errors are returned instead
+func (adminHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if !admins.authorize(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportRequestSize)
+	res, err := directory.Import(r.URL.Query().Get("hostname"), r.Body)
+	if err != nil {
+		rlog.Errorf("could not import directory [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(res.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(res)
+	return
+}
+
+// Effective returns the params and variables a directory user ends up with after inheritance
+func (adminHandler) Effective(w http.ResponseWriter, r *http.Request) {
+	if !admins.authorize(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	e, err := directory.Effective(q.Get("hostname"), q.Get("domain"), q.Get("user"))
+	if err != nil {
+		rlog.Infof("could not resolve effective directory user [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e)
+	return
+}
+
+// Schedules lists the dialplan schedules of a hostname or group of hosts
+func (adminHandler) Schedules(w http.ResponseWriter, r *http.Request) {
+	if !admins.authorize(w, r) {
+		return
+	}
+	s, err := dialplan.Schedules(r.URL.Query().Get("hostname"))
+	if err != nil {
+		rlog.Infof("could not list schedules [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+	return
+}
+
+// SetSchedule adds or replaces a dialplan schedule of a hostname or group of hosts
+func (adminHandler) SetSchedule(w http.ResponseWriter, r *http.Request) {
+	s := dialplan.Schedule{}
+	if !admins.decode(w, r, &s) {
+		return
+	}
+	if err := dialplan.SetSchedule(r.URL.Query().Get("hostname"), s); err != nil {
+		rlog.Errorf("could not set schedule [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// DeleteSchedule removes a dialplan schedule that no did uses anymore
+func (adminHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if !admins.authorize(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	if err := dialplan.DeleteSchedule(q.Get("hostname"), q.Get("name")); err != nil {
+		rlog.Errorf("could not delete schedule [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// Blocklist returns the caller blocklist of a domain, or the global one without a domain
+func (adminHandler) Blocklist(w http.ResponseWriter, r *http.Request) {
+	if !admins.authorize(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	b, err := dialplan.GetBlocklist(q.Get("hostname"), q.Get("domain"))
+	if err != nil {
+		rlog.Infof("could not get blocklist [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(b)
+	return
+}
+
+// SetBlocklist replaces the caller blocklist of a domain, or the global one without a domain
+func (adminHandler) SetBlocklist(w http.ResponseWriter, r *http.Request) {
+	b := dialplan.Blocklist{}
+	if !admins.decode(w, r, &b) {
+		return
+	}
+	q := r.URL.Query()
+	if err := dialplan.SetBlocklist(q.Get("hostname"), q.Get("domain"), b); err != nil {
+		rlog.Errorf("could not set blocklist [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// Block adds a number or prefix to a caller blocklist
+func (adminHandler) Block(w http.ResponseWriter, r *http.Request) {
+	req := blocklistEntryRequest{}
+	if !admins.decode(w, r, &req) {
+		return
+	}
+	q := r.URL.Query()
+	if err := dialplan.Block(q.Get("hostname"), q.Get("domain"), req.Number, req.Prefix); err != nil {
+		rlog.Errorf("could not block caller [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// Unblock removes a number or prefix from a caller blocklist
+func (adminHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	req := blocklistEntryRequest{}
+	if !admins.decode(w, r, &req) {
+		return
+	}
+	q := r.URL.Query()
+	if err := dialplan.Unblock(q.Get("hostname"), q.Get("domain"), req.Number, req.Prefix); err != nil {
+		rlog.Errorf("could not unblock caller [%s]", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return
+}
+
+// authorize checks the basic auth credentials of an admin request
+func (adminHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if !admin.Enabled() {
+		http.NotFound(w, r)
+		return false
+	}
+	user, pass, ok := r.BasicAuth()
+	if !admin.Authorized(user, pass, ok) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// decode authorizes the request and decodes its json body into v
+func (adminHandler) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if !admins.authorize(w, r) {
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxAdminRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
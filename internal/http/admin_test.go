@@ -0,0 +1,359 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/admin"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/dialplan"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+)
+
+// adminModuleData points the directory and dialplan sections at a scratch copy of the module data,
+// so admin requests don't change the fixtures used by the other tests
+func adminModuleData(t *testing.T) func() {
+	_, restore := moduleDataFixture(t, []string{"../../moduledata/directory.json", "../../moduledata/dialplan.json"}, directory.New, dialplan.New)
+	os.Setenv("FS_XML_ADMIN_PASSWORD", "admin-secret")
+	admin.New("admin", "env:FS_XML_ADMIN_PASSWORD")
+	return func() {
+		admin.New("", "")
+		restore()
+	}
+}
+
+func adminRequest(handler http.HandlerFunc, user string, pass string, body string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(body))
+	r.Header.Add("Content-Type", "application/json")
+	r.SetBasicAuth(user, pass)
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestAdminSetPassword(t *testing.T) {
+	defer adminModuleData(t)()
+
+	w := adminRequest(admins.SetPassword, "admin", "admin-secret", `{"hostname":"fs-01","domain":"example.com","user":"1002","password":"new-secret"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = directoryRequest(map[string]string{"action": "sip_auth", "user": "1002"})
+	expect := `<param name="a1-hash" value="` + directory.A1Hash("1002", "example.com", "new-secret") + `"/>`
+	if !strings.Contains(w.Body.String(), expect) {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("plaintext password served:\n%s\n", w.Body.String())
+	}
+}
+
+func TestAdminSetPasswordUnauthorized(t *testing.T) {
+	defer adminModuleData(t)()
+
+	w := adminRequest(admins.SetPassword, "admin", "wrong", `{"hostname":"fs-01","domain":"example.com","user":"1002","password":"new-secret"}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+	w = adminRequest(admins.SetPassword, "admin", "admin-secret", `{"hostname":"fs-01","domain":"example.com","user":"2000","password":"new-secret"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAdminRenameDomain(t *testing.T) {
+	defer adminModuleData(t)()
+
+	w := adminRequest(admins.RenameDomain, "admin", "admin-secret", `{"hostname":"fs-01","domain":"example.com","new_domain":"example.org","passwords":{"1000":"correct-horse"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusOK, w.Code, w.Body.String())
+	}
+	resp := renameDomainResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(resp.Stale, ",") != "1001" {
+		t.Errorf("Expected stale users [1001], got %v", resp.Stale)
+	}
+
+	w = directoryRequest(map[string]string{"action": "sip_auth", "user": "1000", "domain": "example.org", "key_value": "example.org"})
+	expect := `<param name="a1-hash" value="` + directory.A1Hash("1000", "example.org", "correct-horse") + `"/>`
+	if !strings.Contains(w.Body.String(), expect) {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+	w = directoryRequest(map[string]string{"action": "sip_auth", "user": "1001", "domain": "example.org", "key_value": "example.org"})
+	if !strings.Contains(w.Body.String(), `status="not found"`) {
+		t.Errorf("stale a1-hash served:\n%s\n", w.Body.String())
+	}
+}
+
+func adminImportRequest(hostname string, body string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest("POST", "http://nowhere.local?hostname="+hostname, strings.NewReader(body))
+	r.Header.Add("Content-Type", "text/csv")
+	r.SetBasicAuth("admin", "admin-secret")
+	w := httptest.NewRecorder()
+	admins.Import(w, r)
+	return w
+}
+
+func TestAdminImport(t *testing.T) {
+	defer adminModuleData(t)()
+
+	csv := `extension,domain,password,caller_id_name,caller_id_number,voicemail_pin,group,accountcode
+1000,example.com,,Alice Smith,,,support,
+2000,example.com,dave-secret,Dave,+15555552000,2000,sales;support,acme
+`
+	w := adminImportRequest("fs-01", csv)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusOK, w.Code, w.Body.String())
+	}
+	expect := `{"created":1,"updated":1}` + "\n"
+	if w.Body.String() != expect {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+
+	w = directoryRequest(map[string]string{"action": "sip_auth", "user": "2000"})
+	expect = `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <variables>
+                <variable name="user_context" value="default"/>
+            </variables>
+            <users>
+                <user id="2000">
+                    <params>
+                        <param name="a1-hash" value="` + directory.A1Hash("2000", "example.com", "dave-secret") + `"/>
+                    </params>
+                    <variables>
+                        <variable name="callgroup" value="sales"/>
+                        <variable name="effective_caller_id_number" value="+15555552000"/>
+                        <variable name="effective_caller_id_name" value="Dave"/>
+                        <variable name="accountcode" value="acme"/>
+                    </variables>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`
+	if w.Body.String() != expect {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+
+	w = directoryRequest(map[string]string{"action": "group_call", "group_name": "support"})
+	if !strings.Contains(w.Body.String(), `<user id="1000" type="pointer"/>`) || !strings.Contains(w.Body.String(), `<user id="2000" type="pointer"/>`) {
+		t.Errorf("group not updated:\n%s\n", w.Body.String())
+	}
+}
+
+func TestAdminImportInvalid(t *testing.T) {
+	defer adminModuleData(t)()
+
+	csv := `extension,domain,password,caller_id_number,voicemail_pin
+2000,example.com,secret-2000,,
+2001,example.com,,,
+2002,example.net,secret-2002,,
+2003,example.com,secret-2003,not-a-number,
+2004,example.com,secret-2004,,12
+2000,example.com,secret-2000,,
+`
+	w := adminImportRequest("fs-01", csv)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	}
+	expect := `{"created":0,"updated":0,"errors":[` +
+		`{"row":3,"error":"password is required for a new user"},` +
+		`{"row":4,"error":"domain not found [example.net]"},` +
+		`{"row":5,"error":"invalid caller id number [not-a-number]"},` +
+		`{"row":6,"error":"voicemail pin must be at least 4 digits"},` +
+		`{"row":7,"error":"duplicate of row 2"}]}` + "\n"
+	if w.Body.String() != expect {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+
+	// valid rows of a rejected import are not written either
+	w = directoryRequest(map[string]string{"action": "sip_auth", "user": "2000"})
+	if !strings.Contains(w.Body.String(), `status="not found"`) {
+		t.Errorf("rejected import was written:\n%s\n", w.Body.String())
+	}
+
+	w = adminImportRequest("fs-01", "domain,password\nexample.com,secret\n")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAdminEffective(t *testing.T) {
+	defer adminModuleData(t)()
+
+	r, _ := http.NewRequest("GET", "http://nowhere.local?hostname=fs-01&domain=example.com&user=1001", nil)
+	r.SetBasicAuth("admin", "admin-secret")
+	w := httptest.NewRecorder()
+	admins.Effective(w, r)
+	expect := `{"id":"1001","domain":"example.com","groups":["sales"],"params":[` +
+		`{"name":"dial-string","value":"{presence_id=1001@example.com}${sofia_contact(1001@example.com)},user/1001-mobile@example.com","source":"user"},` +
+		`{"name":"vm-password","value":"1001","source":"user"}],"variables":[` +
+		`{"name":"user_context","value":"default","source":"domain"},` +
+		`{"name":"callgroup","value":"sales","source":"group:sales"},` +
+		`{"name":"effective_caller_id_number","value":"5000","source":"group:sales"},` +
+		`{"name":"effective_caller_id_name","value":"Bob","source":"user"}]}` + "\n"
+	if w.Body.String() != expect {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+
+	r, _ = http.NewRequest("GET", "http://nowhere.local?hostname=fs-01&domain=example.com&user=2000", nil)
+	r.SetBasicAuth("admin", "admin-secret")
+	w = httptest.NewRecorder()
+	admins.Effective(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func adminScheduleRequest(method string, query string, body string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest(method, "http://nowhere.local?"+query, strings.NewReader(body))
+	r.SetBasicAuth("admin", "admin-secret")
+	w := httptest.NewRecorder()
+	switch method {
+	case "GET":
+		admins.Schedules(w, r)
+	case "POST":
+		admins.SetSchedule(w, r)
+	case "DELETE":
+		admins.DeleteSchedule(w, r)
+	}
+	return w
+}
+
+func TestAdminSchedules(t *testing.T) {
+	defer adminModuleData(t)()
+
+	// replaces the schedule fs-01 inherits from its group
+	w := adminScheduleRequest("POST", "hostname=group:us", `{"name":"support_hours","timezone":"America/Chicago","hours":[{"days":["mon","wed-fri"],"open":"08:30","close":"18:00"}],"holidays":[{"name":"Independence Day","date":"2026-07-04"}]}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = dialplanRequest(map[string]string{"Caller-Destination-Number": "+15555550106"})
+	for _, expect := range []string{
+		`<action application="set" data="timezone=America/Chicago" inline="true"/>`,
+		`<condition wday="2,4-6" time-of-day="08:30-18:00">`,
+		`<condition date-time="2026-07-04 00:00~2026-07-05 00:00">`,
+		`<anti-action application="transfer" data="support_voicemail XML default"/>`,
+	} {
+		if !strings.Contains(w.Body.String(), expect) {
+			t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+		}
+	}
+
+	w = adminScheduleRequest("POST", "hostname=group:us", `{"name":"unused","timezone":"UTC"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = adminScheduleRequest("GET", "hostname=group:us", "")
+	s := []dialplan.Schedule{}
+	if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 || s[0].Name != "support_hours" || s[1].Name != "unused" {
+		t.Errorf("Expected schedules [support_hours unused], got %v", s)
+	}
+
+	invalid := []string{
+		`{"name":"bad","timezone":"Mars/Olympus","hours":[]}`,
+		`{"name":"bad","timezone":"UTC","hours":[{"days":["mon-sun"],"open":"22:00","close":"06:00"}]}`,
+		`{"name":"bad","timezone":"UTC","hours":[{"days":["fri-mon"],"open":"09:00","close":"17:00"}]}`,
+		`{"name":"bad","timezone":"UTC","holidays":[{"name":"x","date":"25/12/2026"}]}`,
+	}
+	for _, body := range invalid {
+		w = adminScheduleRequest("POST", "hostname=group:us", body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d [%s]", http.StatusBadRequest, w.Code, body)
+		}
+	}
+
+	// schedules used by a did are kept
+	w = adminScheduleRequest("DELETE", "hostname=group:us&name=support_hours", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	w = adminScheduleRequest("DELETE", "hostname=group:us&name=unused", "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	// once fs-01 has schedules of its own the group's are no longer used by its dids
+	w = adminScheduleRequest("POST", "hostname=fs-01", `{"name":"support_hours","timezone":"UTC"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = adminScheduleRequest("DELETE", "hostname=group:us&name=support_hours", "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+}
+
+func adminBlocklistRequest(handler http.HandlerFunc, query string, body string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest("POST", "http://nowhere.local?"+query, strings.NewReader(body))
+	r.SetBasicAuth("admin", "admin-secret")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestAdminBlocklist(t *testing.T) {
+	defer adminModuleData(t)()
+
+	// numbers are stored in E.164 whatever way they are written
+	w := adminBlocklistRequest(admins.Block, "hostname=fs-01", `{"number":"(555) 555-0199"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = dialplanRequest(map[string]string{"Caller-Destination-Number": "+15555550100", "Caller-Caller-ID-Number": "+15555550199"})
+	if !strings.Contains(w.Body.String(), `data="blocked_by=global"`) {
+		t.Errorf("Expected the caller to be blocked, got:\n%s\n", w.Body.String())
+	}
+
+	w = adminBlocklistRequest(admins.Blocklist, "hostname=fs-01", "")
+	expect := `{"numbers":["+15555550666","+15555550199"],"prefixes":["+1900"],"anonymous":false}` + "\n"
+	if w.Body.String() != expect {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+
+	w = adminBlocklistRequest(admins.Unblock, "hostname=fs-01", `{"number":"15555550199"}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = dialplanRequest(map[string]string{"Caller-Destination-Number": "+15555550100", "Caller-Caller-ID-Number": "+15555550199"})
+	if !strings.Contains(w.Body.String(), `<extension name="did_15555550100">`) {
+		t.Errorf("Expected the caller to be unblocked, got:\n%s\n", w.Body.String())
+	}
+
+	w = adminBlocklistRequest(admins.SetBlocklist, "hostname=fs-01&domain=example.com", `{"numbers":[],"prefixes":["+1555555"],"anonymous":false,"treatment":{"hangup_cause":"UNALLOCATED_NUMBER"}}`)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d [%s]", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	w = dialplanRequest(map[string]string{"Caller-Destination-Number": "+15555550100", "Caller-Caller-ID-Number": "+15555550123"})
+	if !strings.Contains(w.Body.String(), `<action application="hangup" data="UNALLOCATED_NUMBER"/>`) {
+		t.Errorf("Expected the domain treatment, got:\n%s\n", w.Body.String())
+	}
+
+	invalid := []struct {
+		handler http.HandlerFunc
+		body    string
+	}{
+		{admins.Block, `{"number":"12"}`},
+		{admins.Block, `{"prefix":"1900"}`},
+		{admins.Block, `{}`},
+		{admins.Unblock, `{"number":"+15555550100"}`},
+		{admins.SetBlocklist, `{"treatment":{"hangup_cause":"GO_AWAY"}}`},
+	}
+	for _, tt := range invalid {
+		w = adminBlocklistRequest(tt.handler, "hostname=fs-01", tt.body)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d [%s]", http.StatusBadRequest, w.Code, tt.body)
+		}
+	}
+}
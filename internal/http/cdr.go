@@ -0,0 +1,137 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/cdr"
+)
+
+const (
+	// largest cdr post accepted, leg heavy calls with long app logs can reach a few hundred KB
+	maxCDRSize = 8 << 20
+)
+
+var (
+	cdrs cdrHandler
+)
+
+type cdrHandler struct{}
+
+// Post stores a CDR sent by mod_xml_cdr or mod_json_cdr. Both modules either post the document as the
+// `cdr` form value or as the raw request body, the format is picked from the first character
+func (cdrHandler) Post(w http.ResponseWriter, r *http.Request) {
+	if !cdrs.authorize(w, r) {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxCDRSize)
+	var d []byte
+	// FreeSWITCH and proxies may add a charset to the media type
+	if t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); t == "application/x-www-form-urlencoded" {
+		r.ParseForm()
+		d = []byte(requestForm(r.PostForm).Get("cdr"))
+	} else {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			rlog.Errorf("could not read cdr [%s]", err.Error())
+			http.Error(w, "could not read cdr", http.StatusBadRequest)
+			return
+		}
+		d = b
+	}
+	d = bytes.TrimSpace(d)
+
+	var c cdr.Record
+	var err error
+	switch {
+	case len(d) == 0:
+		err = errors.New("empty cdr")
+	case d[0] == '<':
+		c, err = cdr.ParseXML(d)
+	case d[0] == '{':
+		c, err = cdr.ParseJSON(d)
+	default:
+		err = errors.New("unknown cdr format")
+	}
+	if err != nil {
+		rlog.Errorf("could not parse cdr [%s]", err.Error())
+		http.Error(w, "could not parse cdr", http.StatusBadRequest)
+		return
+	}
+	if c.Hostname == "" {
+		c.Hostname = r.URL.Query().Get("hostname")
+	}
+
+	// a non 200 response makes FreeSWITCH retry the post or write the cdr to its error log directory
+	if err = cdr.Store(c); err != nil {
+		rlog.Errorf("could not store cdr [%s]", err.Error())
+		http.Error(w, "could not store cdr", http.StatusInternalServerError)
+		return
+	}
+	rlog.Debugf("stored cdr [%s] [%s]", c.Hostname, c.UUID)
+	return
+}
+
+// Get returns the stored CDRs matching the `hostname`, `caller`, `destination`, `from` and `to` query
+// parameters as json. Times are RFC 3339
+func (cdrHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !cdrs.authorize(w, r) {
+		return
+	}
+	v := r.URL.Query()
+	q := cdr.Query{
+		Hostname:    v.Get("hostname"),
+		Caller:      v.Get("caller"),
+		Destination: v.Get("destination"),
+	}
+	var err error
+	if s := v.Get("from"); s != "" {
+		if q.From, err = time.Parse(time.RFC3339, s); err != nil {
+			http.Error(w, "invalid from time", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := v.Get("to"); s != "" {
+		if q.To, err = time.Parse(time.RFC3339, s); err != nil {
+			http.Error(w, "invalid to time", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		if q.Limit, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	res, err := cdr.Find(q)
+	if err != nil {
+		rlog.Errorf("could not query cdrs [%s]", err.Error())
+		http.Error(w, "could not query cdrs", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+	return
+}
+
+func (cdrHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if !cdr.Enabled() {
+		http.NotFound(w, r)
+		return false
+	}
+	user, pass, ok := r.BasicAuth()
+	if !cdr.Authorized(user, pass, ok) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="cdr"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
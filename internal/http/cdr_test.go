@@ -0,0 +1,222 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/cdr"
+)
+
+const (
+	testXMLCDR = `<?xml version="1.0"?>
+<cdr core-uuid="1b6f2a38-2c1e-4c5e-9d7a-2f0f5f0d4c11" switchname="fs-01">
+  <channel_data>
+    <direction>inbound</direction>
+  </channel_data>
+  <variables>
+    <uuid>6a2b5c10-0d4e-4a8e-8f65-0c1c6d1a7e01</uuid>
+    <hangup_cause>NORMAL_CLEARING</hangup_cause>
+    <start_epoch>1700000000</start_epoch>
+    <answer_epoch>1700000005</answer_epoch>
+    <end_epoch>1700000065</end_epoch>
+    <duration>65</duration>
+    <billsec>60</billsec>
+  </variables>
+  <callflow dialplan="XML">
+    <caller_profile>
+      <caller_id_name>Alice Smith</caller_id_name>
+      <caller_id_number>15551230001</caller_id_number>
+      <destination_number>1000</destination_number>
+      <context>public</context>
+    </caller_profile>
+  </callflow>
+</cdr>`
+
+	testJSONCDR = `{
+  "core-uuid": "1b6f2a38-2c1e-4c5e-9d7a-2f0f5f0d4c11",
+  "switchname": "fs-02",
+  "channel_data": {"direction": "outbound"},
+  "variables": {
+    "uuid": "0f3d8a1e-77b4-4f0e-9a55-3b2a8c9d6e02",
+    "hangup_cause": "USER_BUSY",
+    "start_epoch": "1700086400",
+    "answer_epoch": "0",
+    "end_epoch": "1700086410",
+    "duration": "10",
+    "billsec": "0"
+  },
+  "callflow": [{
+    "caller_profile": {
+      "caller_id_name": "Bob",
+      "caller_id_number": "1001",
+      "destination_number": "15551230002",
+      "context": "internal"
+    }
+  }]
+}`
+)
+
+func TestCDRPostAndQuery(t *testing.T) {
+	d, err := os.MkdirTemp("", "cdr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+	if err = cdr.New(d, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	defer cdr.New("", "", "")
+
+	// mod_xml_cdr posts the url encoded document as the cdr form value
+	form := url.Values{}
+	form.Add("cdr", testXMLCDR)
+	r, _ := http.NewRequest("POST", "http://nowhere.local/fs/cdr", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	w := httptest.NewRecorder()
+	cdrs.Post(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("xml cdr post returned [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	// mod_json_cdr posting the raw document
+	r, _ = http.NewRequest("POST", "http://nowhere.local/fs/cdr", strings.NewReader(testJSONCDR))
+	r.Header.Add("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	cdrs.Post(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("json cdr post returned [%d] [%s]", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		query  string
+		expect []string
+	}{
+		// newest first, so a limit keeps the recent calls
+		{"", []string{"0f3d8a1e-77b4-4f0e-9a55-3b2a8c9d6e02", "6a2b5c10-0d4e-4a8e-8f65-0c1c6d1a7e01"}},
+		{"limit=1", []string{"0f3d8a1e-77b4-4f0e-9a55-3b2a8c9d6e02"}},
+		{"hostname=fs-01", []string{"6a2b5c10-0d4e-4a8e-8f65-0c1c6d1a7e01"}},
+		{"caller=1001", []string{"0f3d8a1e-77b4-4f0e-9a55-3b2a8c9d6e02"}},
+		{"destination=1000", []string{"6a2b5c10-0d4e-4a8e-8f65-0c1c6d1a7e01"}},
+		{"from=2023-11-14T00:00:00Z&to=2023-11-14T23:59:59Z", []string{"6a2b5c10-0d4e-4a8e-8f65-0c1c6d1a7e01"}},
+		{"from=2023-11-15T00:00:00Z", []string{"0f3d8a1e-77b4-4f0e-9a55-3b2a8c9d6e02"}},
+		{"hostname=fs-03", []string{}},
+	}
+	for _, tt := range tests {
+		r, _ = http.NewRequest("GET", "http://nowhere.local/fs/cdr?"+tt.query, nil)
+		w = httptest.NewRecorder()
+		cdrs.Get(w, r)
+		res := []cdr.Record{}
+		if err = json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("query [%s] returned invalid json [%s]", tt.query, err.Error())
+		}
+		got := []string{}
+		for _, c := range res {
+			got = append(got, c.UUID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expect, ",") {
+			t.Errorf("query [%s]\n\nExpected:\n%v\n\nGot:\n%v\n", tt.query, tt.expect, got)
+		}
+	}
+
+	// the index is rebuilt from the day files when the store is set up again
+	if err = cdr.New(d, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	r, _ = http.NewRequest("GET", "http://nowhere.local/fs/cdr?caller=15551230001", nil)
+	w = httptest.NewRecorder()
+	cdrs.Get(w, r)
+	res := []cdr.Record{}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	if len(res) != 1 || res[0].CallerIDName != "Alice Smith" || res[0].Billsec != 60 || res[0].HangupCause != "NORMAL_CLEARING" {
+		t.Errorf("unexpected core fields [%+v]", res)
+	}
+}
+
+// day files older than the retention are removed with their records, late cdrs for them are dropped
+func TestCDRRetention(t *testing.T) {
+	d, err := os.MkdirTemp("", "cdr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+	now := time.Now().UTC()
+	days := []struct {
+		start time.Time
+		uuid  string
+	}{
+		{now.AddDate(0, 0, -10), "3c1e0f52-5b7a-4d0e-8a1b-6f2d9e4c7a03"},
+		{now, "8e4d2a61-9c3b-4f1a-b7e5-1d0c6a3f2b04"},
+	}
+	for _, day := range days {
+		b, _ := json.Marshal(cdr.Record{UUID: day.uuid, Hostname: "fs-01", StartTime: day.start})
+		f := filepath.Join(d, "cdr-"+day.start.Format("2006-01-02")+".jsonl")
+		if err = os.WriteFile(f, append(b, '\n'), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = cdr.New(d, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	defer cdr.New("", "", "")
+	if err = cdr.Retention(7); err != nil {
+		t.Fatal(err)
+	}
+	defer cdr.Retention(0)
+
+	if _, err = os.Stat(filepath.Join(d, "cdr-"+days[0].start.Format("2006-01-02")+".jsonl")); err == nil {
+		t.Errorf("Expected day file past the retention to be removed")
+	}
+	// testJSONCDR started in 2023
+	r, _ := http.NewRequest("POST", "http://nowhere.local/fs/cdr", strings.NewReader(testJSONCDR))
+	r.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	cdrs.Post(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("json cdr post returned [%d] [%s]", w.Code, w.Body.String())
+	}
+	res, err := cdr.Find(cdr.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].UUID != days[1].uuid {
+		t.Errorf("Expected only the record of today, got [%+v]", res)
+	}
+	if err = cdr.Retention(-1); err == nil {
+		t.Errorf("Expected negative retention to be rejected")
+	}
+}
+
+func TestCDRBasicAuth(t *testing.T) {
+	d, err := os.MkdirTemp("", "cdr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+	os.Setenv("FS_TEST_CDR_PASSWORD", "secret")
+	if err = cdr.New(d, "freeswitch", "env:FS_TEST_CDR_PASSWORD"); err != nil {
+		t.Fatal(err)
+	}
+	defer cdr.New("", "", "")
+
+	r, _ := http.NewRequest("POST", "http://nowhere.local/fs/cdr", strings.NewReader(testJSONCDR))
+	w := httptest.NewRecorder()
+	cdrs.Post(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("post without credentials returned [%d]", w.Code)
+	}
+
+	r, _ = http.NewRequest("POST", "http://nowhere.local/fs/cdr", strings.NewReader(testJSONCDR))
+	r.SetBasicAuth("freeswitch", "secret")
+	w = httptest.NewRecorder()
+	cdrs.Post(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("post with credentials returned [%d] [%s]", w.Code, w.Body.String())
+	}
+}
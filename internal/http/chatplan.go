@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/chatplan"
+)
+
+const (
+	defaultChatplanContext = "default"
+)
+
+var (
+	chatplans chatplanHandler
+)
+
+type chatplanHandler struct{}
+
+func (chatplanHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	cr := requestForm(r.PostForm)
+
+	// mod_sms locates the chatplan without a key, the context is in the event headers
+	c := cr.Get("context")
+	if c == "" {
+		c = defaultChatplanContext
+	}
+	err := chatplan.Handler(r.Context(), c, cr.Get("hostname"), w)
+	if err != nil {
+		rlog.Errorf("could not load chatplan [%s]", err.Error())
+		notFound(w)
+	}
+	return
+}
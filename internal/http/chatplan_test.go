@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// chatplanRequest posts what mod_sms sends, the context is an event header and not a key
+func chatplanRequest(hostname string, context string) *httptest.ResponseRecorder {
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", hostname)
+	form.Add("section", "chatplan")
+	if context != "" {
+		form.Add("context", context)
+	}
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	chatplans.Handler(w, r)
+	return w
+}
+
+func TestChatplanHandler(t *testing.T) {
+	defaultContext := `<document type="freeswitch/xml">
+    <section name="chatplan" description="Regex Chat Plan">
+        <context name="default">
+            <extension name="sip_support">
+                <condition field="proto" expression="^sip$">
+                </condition>
+                <condition field="to_user" expression="^support$">
+                    <action application="lua" data="support_ticket.lua"/>
+                    <action application="reply" data="Thanks, a ticket has been opened"/>
+                </condition>
+            </extension>
+            <extension name="forward_sales">
+                <condition field="to_user" expression="^sales$">
+                    <action application="send" data=""/>
+                    <anti-action application="stop" data=""/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`
+	tests := []struct {
+		name    string
+		context string
+		expect  string
+	}{
+		{"default", "default", defaultContext},
+		{"no context header", "", defaultContext},
+		{"public", "public", `<document type="freeswitch/xml">
+    <section name="chatplan" description="Regex Chat Plan">
+        <context name="public">
+            <extension name="inbound_sms">
+                <condition field="to_user" expression="^\+?1?5555550100$">
+                    <action application="set" data="to_user=1000"/>
+                    <action application="send" data=""/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+	}
+	for _, tt := range tests {
+		w := chatplanRequest("fs-01", tt.context)
+		if w.Body.String() != tt.expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, tt.expect, w.Body.String())
+		}
+	}
+}
+
+func TestChatplanHandlerNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+	tests := []struct {
+		name     string
+		hostname string
+		context  string
+	}{
+		{"unknown hostname", "fs-02", "default"},
+		{"unknown context", "fs-01", "missing"},
+	}
+	for _, tt := range tests {
+		w := chatplanRequest(tt.hostname, tt.context)
+		if w.Body.String() != expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, expect, w.Body.String())
+		}
+	}
+}
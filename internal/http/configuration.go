@@ -1,17 +1,53 @@
 package http
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/romana/rlog"
 
 	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/acl"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/amqp"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/console"
 	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/distributor"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/eventsocket"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/fifo"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/generic"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/hiredis"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/localstream"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/logfile"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/nibblebill"
 	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/sofia"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/spandsp"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/switchconf"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/syslog"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/verto"
 )
 
 var (
 	configuration configurationHandler
+
+	// configurations served by a module of their own, generic modules can not use these names
+	builtinConfs = map[string]func(ctx context.Context, hostname string, w http.ResponseWriter) error{
+		"acl.conf":          acl.Handler,
+		"amqp.conf":         amqp.Handler,
+		"console.conf":      console.Handler,
+		"distributor.conf":  distributor.Handler,
+		"event_socket.conf": eventsocket.Handler,
+		"fifo.conf":         fifo.Handler,
+		"hiredis.conf":      hiredis.Handler,
+		"local_stream.conf": localstream.Handler,
+		"logfile.conf":      logfile.Handler,
+		"modules.conf":      modules.Handler,
+		"nibblebill.conf":   nibblebill.Handler,
+		"sofia.conf":        sofia.Handler,
+		"spandsp.conf":      spandsp.Handler,
+		"switch.conf":       switchconf.Handler,
+		"syslog.conf":       syslog.Handler,
+		"verto.conf":        verto.Handler,
+	}
 )
 
 type configurationHandler struct{}
@@ -33,14 +69,12 @@ func (configurationHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
-	switch cr.Get("key_value") {
-	case "acl.conf":
-		err = acl.Handler(ctx, cr.Get("hostname"), w)
-	case "distributor.conf":
-		err = distributor.Handler(ctx, cr.Get("hostname"), w)
-	case "sofia.conf":
-		err = sofia.Handler(ctx, cr.Get("hostname"), w)
-	default:
+	if handler, ok := builtinConfs[cr.Get("key_value")]; ok {
+		err = handler(ctx, cr.Get("hostname"), w)
+	} else if generic.Handles(cr.Get("key_value")) {
+		// confs declared in config.json
+		err = generic.Handler(ctx, cr.Get("key_value"), cr.Get("hostname"), w)
+	} else {
 		rlog.Infof("configuration request not supported [%s]", cr.Get("key_value"))
 		notFound(w)
 		return
@@ -53,3 +87,14 @@ func (configurationHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	}
 	return
 }
+
+// checkGenericModules makes sure every generic module is served, a name already used by a built in
+// configuration would never reach it
+func checkGenericModules(c []generic.Conf) error {
+	for _, g := range c {
+		if _, ok := builtinConfs[g.Name]; ok {
+			return fmt.Errorf("generic module uses the name of a built in configuration [%s]", g.Name)
+		}
+	}
+	return nil
+}
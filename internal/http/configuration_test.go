@@ -10,8 +10,26 @@ import (
 	"testing"
 
     "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/acl"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/amqp"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/console"
     "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/distributor"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/eventsocket"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/fifo"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/generic"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/hiredis"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/localstream"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/logfile"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/nibblebill"
     "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/sofia"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/spandsp"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/switchconf"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/syslog"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/verto"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/chatplan"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/dialplan"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+    "github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/phrases"
 )
 
 func TestMain(m *testing.M) {
@@ -21,14 +39,163 @@ func TestMain(m *testing.M) {
 
 	// init each module for testing
 	acl.New(moduleData, templatePath)
+	amqp.New(moduleData, templatePath)
+	console.New(moduleData, templatePath)
 	distributor.New(moduleData, templatePath)
+	eventsocket.New(moduleData, templatePath)
+	fifo.New(moduleData, templatePath)
+	hiredis.New(moduleData, templatePath)
+	localstream.New(moduleData, templatePath)
+	logfile.New(moduleData, templatePath)
+	modules.New(moduleData, templatePath)
+	nibblebill.New(moduleData, templatePath)
 	sofia.New(moduleData, templatePath)
+	spandsp.New(moduleData, templatePath)
+	switchconf.New(moduleData, templatePath)
+	syslog.New(moduleData, templatePath)
+	verto.New(moduleData, templatePath)
+	generic.New(moduleData, templatePath, []generic.Conf{{
+		Name:           "cdr_csv.conf",
+		ModuleDataFile: "cdr_csv.json",
+		Template:       "configuration/generic/generic.xml",
+	}})
+
+	// init each section for testing
+	chatplan.New(moduleData, templatePath)
+	dialplan.New(moduleData, templatePath)
+	directory.New(moduleData, templatePath)
+	phrases.New(moduleData, templatePath)
+
+	// secrets referenced by module data
+	os.Setenv("FS_AMQP_US_EAST_PASSWORD", "rabbit")
+	os.Setenv("FS_EVENT_SOCKET_PASSWORD", "ClueCon")
+	os.Setenv("FS_HIREDIS_US_EAST_PASSWORD", "redis")
+	os.Setenv("FS_NIBBLEBILL_DSN", "pgsql://hostaddr=10.0.0.5 dbname=billing user=fs password=fs")
 
 	notFoundTemplatePath = filepath.Join(templatePath, notFoundTemplate)
 
 	os.Exit(m.Run())
 }
 
+func TestConfigHandlerAMQP(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="amqp.conf" description="AMQP Event Publishing">
+            <producers>
+                <profile name="events">
+                    <connections>
+                        <connection name="main">
+                            <param name="hostname" value="rabbitmq-01.us-east.local"/>
+                            <param name="virtualhost" value="/freeswitch"/>
+                            <param name="username" value="freeswitch"/>
+                            <param name="password" value="rabbit"/>
+                            <param name="port" value="5672"/>
+                            <param name="heartbeat" value="30"/>
+                        </connection>
+                        <connection name="fallback">
+                            <param name="hostname" value="rabbitmq-02.us-east.local"/>
+                            <param name="virtualhost" value="/freeswitch"/>
+                            <param name="username" value="freeswitch"/>
+                            <param name="password" value="rabbit"/>
+                            <param name="port" value="5672"/>
+                            <param name="heartbeat" value="30"/>
+                        </connection>
+                    </connections>
+                    <params>
+                        <param name="exchange-name" value="freeswitch.events"/>
+                        <param name="exchange-type" value="topic"/>
+                        <param name="event_filter" value="SWITCH_EVENT_CHANNEL_CREATE,CHANNEL_ANSWER,SWITCH_EVENT_CHANNEL_HANGUP_COMPLETE"/>
+                        <param name="circuit_breaker_ms" value="10000"/>
+                        <param name="reconnect_interval_ms" value="1000"/>
+                        <param name="send_queue_size" value="5000"/>
+                    </params>
+                </profile>
+            </producers>
+            <commands>
+                <profile name="commands">
+                    <connections>
+                        <connection name="main">
+                            <param name="hostname" value="rabbitmq-01.us-east.local"/>
+                            <param name="virtualhost" value="/freeswitch"/>
+                            <param name="username" value="freeswitch"/>
+                            <param name="password" value="rabbit"/>
+                            <param name="port" value="5672"/>
+                            <param name="heartbeat" value="30"/>
+                        </connection>
+                    </connections>
+                    <params>
+                        <param name="exchange-name" value="freeswitch.commands"/>
+                        <param name="exchange-type" value="topic"/>
+                        <param name="binding_key" value="commands.us-east"/>
+                    </params>
+                </profile>
+            </commands>
+            <logging>
+                <profile name="logs">
+                    <connections>
+                        <connection name="main">
+                            <param name="hostname" value="rabbitmq-01.us-east.local"/>
+                            <param name="virtualhost" value="/freeswitch"/>
+                            <param name="username" value="freeswitch"/>
+                            <param name="password" value="rabbit"/>
+                            <param name="port" value="5672"/>
+                            <param name="heartbeat" value="30"/>
+                        </connection>
+                    </connections>
+                    <params>
+                        <param name="exchange-name" value="freeswitch.logging"/>
+                        <param name="log-levels" value="warning,err,crit,alert"/>
+                    </params>
+                </profile>
+            </logging>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "amqp.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerAMQPNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "amqp.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
 func TestConfigHandlerAcl(t *testing.T) {
 	expect := `<document type="freeswitch/xml">
     <section name="configuration" description="FreeSWITCH Configuration">
@@ -90,16 +257,668 @@ func TestConfigHandlerAclNotFound(t *testing.T) {
 	}
 }
 
-func TestConfigHandlerDistributor(t *testing.T) {
+func TestConfigHandlerConsole(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="console.conf" description="Console Logger">
+            <mappings>
+                <map name="all" value="console,debug,info,notice,warning,err,crit,alert"/>
+            </mappings>
+            <settings>
+                <param name="colorize" value="true"/>
+                <param name="loglevel" value="info"/>
+            </settings>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "console.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerConsoleNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "console.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerDistributor(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="distributor.conf" description="Distributor Configuration">
+            <lists>
+                <list name="proxy" default="2">
+                    <node name="proxy-01.local" weight="1"/>
+                    <node name="proxy-02.local" weight="1"/>
+                </list>
+            </lists>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "distributor.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerDistributorNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "distributor.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerEventSocket(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="event_socket.conf" description="Socket Client">
+            <settings>
+                <param name="nat-map" value="false"/>
+                <param name="listen-ip" value="127.0.0.1"/>
+                <param name="listen-port" value="8021"/>
+                <param name="password" value="ClueCon"/>
+                <param name="apply-inbound-acl" value="lan"/>
+            </settings>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "event_socket.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerEventSocketNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "event_socket.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerFifo(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="fifo.conf" description="FIFO Configuration">
+            <settings>
+                <param name="delete-all-outbound-member-on-startup" value="false"/>
+                <param name="outbound-strategy" value="ringall"/>
+            </settings>
+            <fifos>
+                <fifo name="support@$${domain}" importance="0">
+                    <member timeout="60" simo="1" lag="20">{member_wait=nowait}user/1005@$${domain}</member>
+                    <member timeout="60" simo="1" lag="20">{member_wait=nowait}user/1006@$${domain}</member>
+                </fifo>
+                <fifo name="sales@$${domain}" importance="1">
+                </fifo>
+            </fifos>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "fifo.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerFifoNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "fifo.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerGeneric(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="cdr_csv.conf" description="cdr_csv.conf">
+            <settings>
+                <param name="default-template" value="example"/>
+                <param name="rotate-on-hup" value="true"/>
+                <param name="legs" value="a"/>
+            </settings>
+            <templates>
+                <template name="example">"${caller_id_name}","${caller_id_number}","${destination_number}","${start_stamp}","${billsec}","${hangup_cause}"
+</template>
+            </templates>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "cdr_csv.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerGenericNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "cdr_csv.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+// elements that can not be rendered give not found instead of a truncated document
+func TestConfigHandlerGenericInvalid(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+	wd, _ := os.Getwd()
+	templatePath := filepath.Join(wd, "../../templates")
+	c := []generic.Conf{{
+		Name:           "cdr_csv.conf",
+		ModuleDataFile: "cdr_csv.json",
+		Template:       "configuration/generic/generic.xml",
+	}}
+	generic.New(filepath.Join(wd, "testdata/generic"), templatePath, c)
+	defer generic.New(filepath.Join(wd, "../../moduledata"), templatePath, c)
+
+	for _, hostname := range []string{"fs-no-tag", "fs-text-and-params", "fs-tag-markup", "fs-attribute-markup", "fs-param-no-name", "fs-param-no-value", "fs-null-text"} {
+		w := configurationRequest(hostname, "cdr_csv.conf")
+		if w.Body.String() != expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", hostname, expect, w.Body.String())
+		}
+	}
+}
+
+// generic modules named like a built in configuration or another generic module are never served
+func TestGenericModulesConflicting(t *testing.T) {
+	wd, _ := os.Getwd()
+	templatePath := filepath.Join(wd, "../../templates")
+	c := generic.Conf{
+		Name:           "cdr_csv.conf",
+		ModuleDataFile: "cdr_csv.json",
+		Template:       "configuration/generic/generic.xml",
+	}
+	if err := checkGenericModules([]generic.Conf{c}); err != nil {
+		t.Errorf("Expected cdr_csv.conf to be accepted, got %s", err.Error())
+	}
+	builtin := c
+	builtin.Name = "sofia.conf"
+	if err := checkGenericModules([]generic.Conf{c, builtin}); err == nil {
+		t.Errorf("Expected sofia.conf to be rejected")
+	}
+	if err := generic.New(filepath.Join(wd, "../../moduledata"), templatePath, []generic.Conf{c, c}); err == nil {
+		t.Errorf("Expected duplicate generic module to be rejected")
+	}
+	if err := generic.New(filepath.Join(wd, "../../moduledata"), templatePath, []generic.Conf{c}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigHandlerHiredis(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="hiredis.conf" description="Redis Connector">
+            <profiles>
+                <profile name="default">
+                    <connections>
+                        <connection name="main">
+                            <param name="hostname" value="redis-01.us-east.local"/>
+                            <param name="password" value="redis"/>
+                            <param name="port" value="6379"/>
+                            <param name="timeout_ms" value="500"/>
+                        </connection>
+                        <connection name="fallback">
+                            <param name="hostname" value="redis-02.us-east.local"/>
+                            <param name="password" value="redis"/>
+                            <param name="port" value="6379"/>
+                            <param name="timeout_ms" value="500"/>
+                        </connection>
+                    </connections>
+                    <params>
+                        <param name="ignore-connect-fail" value="true"/>
+                        <param name="ignore-error" value="true"/>
+                        <param name="max-pipelined-requests" value="20"/>
+                        <param name="delete-when-zero" value="false"/>
+                    </params>
+                </profile>
+            </profiles>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "hiredis.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerHiredisNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "hiredis.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerLocalStream(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="local_stream.conf" description="Stream files from local dir">
+            <directory name="moh/8000" path="$${sounds_dir}/music/8000">
+                <param name="rate" value="8000"/>
+                <param name="shuffle" value="true"/>
+                <param name="channels" value="1"/>
+                <param name="interval" value="20"/>
+                <param name="timer-name" value="soft"/>
+            </directory>
+            <directory name="moh/16000" path="$${sounds_dir}/music/16000">
+                <param name="rate" value="16000"/>
+                <param name="shuffle" value="true"/>
+                <param name="channels" value="1"/>
+                <param name="interval" value="20"/>
+                <param name="timer-name" value="soft"/>
+            </directory>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "local_stream.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerLocalStreamNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "local_stream.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+// every rate mod_local_stream serves is accepted, any other is not
+func TestConfigHandlerLocalStreamRates(t *testing.T) {
+	wd, _ := os.Getwd()
+	templatePath := filepath.Join(wd, "../../templates")
+	localstream.New(filepath.Join(wd, "testdata/localstream"), templatePath)
+	defer localstream.New(filepath.Join(wd, "../../moduledata"), templatePath)
+
+	w := configurationRequest("fs-01", "local_stream.conf")
+	expect := `<directory name="moh/44100" path="$${sounds_dir}/music/44100">
+                <param name="rate" value="44100"/>`
+	if !strings.Contains(w.Body.String(), expect) {
+		t.Errorf("44100\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+	w = configurationRequest("fs-03", "local_stream.conf")
+	if !strings.Contains(w.Body.String(), `status="not found"`) {
+		t.Errorf("12000\n\nExpected not found, got:\n%s\n", w.Body.String())
+	}
+}
+
+func TestConfigHandlerLogfile(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="logfile.conf" description="File Logging">
+            <settings>
+                <param name="rotate-on-hup" value="true"/>
+            </settings>
+            <profiles>
+                <profile name="default">
+                    <settings>
+                        <param name="logfile" value="/var/log/freeswitch/freeswitch.log"/>
+                        <param name="rollover" value="10485760"/>
+                        <param name="maximum-rotate" value="32"/>
+                        <param name="uuid" value="true"/>
+                    </settings>
+                    <mappings>
+                        <map name="all" value="console,debug,info,notice,warning,err,crit,alert"/>
+                    </mappings>
+                </profile>
+            </profiles>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "logfile.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerLogfileNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "logfile.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerModules(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="modules.conf" description="Modules">
+            <modules>
+                <load module="mod_console"/>
+                <load module="mod_event_socket" critical="true"/>
+                <load module="mod_commands"/>
+                <load module="mod_dptools"/>
+                <load module="mod_dialplan_xml"/>
+                <load module="mod_sndfile"/>
+                <load module="mod_native_file"/>
+                <load module="mod_local_stream"/>
+                <load module="mod_tone_stream"/>
+                <load module="mod_sofia" critical="true"/>
+                <load module="mod_distributor"/>
+            </modules>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "modules.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerModulesNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "modules.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerNibblebill(t *testing.T) {
 	expect := `<document type="freeswitch/xml">
     <section name="configuration" description="FreeSWITCH Configuration">
-        <configuration name="distributor.conf" description="Distributor Configuration">
-            <lists>
-                <list name="proxy" default="2">
-                    <node name="proxy-01.local" weight="1"/>
-                    <node name="proxy-02.local" weight="1"/>
-                </list>
-            </lists>
+        <configuration name="nibblebill.conf" description="Nibble Billing">
+            <settings>
+                <param name="odbc-dsn" value="pgsql://hostaddr=10.0.0.5 dbname=billing user=fs password=fs"/>
+                <param name="db_table" value="accounts"/>
+                <param name="db_column_cash" value="cash"/>
+                <param name="db_column_account" value="id"/>
+                <param name="global_heartbeat" value="60"/>
+                <param name="lowbal_amt" value="2.5"/>
+                <param name="lowbal_action" value="play ding"/>
+                <param name="nobal_amt" value="0"/>
+                <param name="nobal_action" value="hangup"/>
+            </settings>
         </configuration>
     </section>
 </document>
@@ -111,7 +930,7 @@ func TestConfigHandlerDistributor(t *testing.T) {
 	form.Add("section", "configuration")
 	form.Add("tag_name", "configuration")
 	form.Add("key_name", "name")
-	form.Add("key_value", "distributor.conf")
+	form.Add("key_value", "nibblebill.conf")
 	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	w := httptest.NewRecorder()
@@ -122,7 +941,7 @@ func TestConfigHandlerDistributor(t *testing.T) {
 	}
 }
 
-func TestConfigHandlerDistributorNotFound(t *testing.T) {
+func TestConfigHandlerNibblebillNotFound(t *testing.T) {
 	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
 <document type="freeswitch/xml">
     <section name="result">
@@ -137,7 +956,7 @@ func TestConfigHandlerDistributorNotFound(t *testing.T) {
 	form.Add("section", "configuration")
 	form.Add("tag_name", "configuration")
 	form.Add("key_name", "name")
-	form.Add("key_value", "distributor.conf")
+	form.Add("key_value", "nibblebill.conf")
 	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
 	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	w := httptest.NewRecorder()
@@ -266,3 +1085,416 @@ func TestConfigHandlerSofiaNotFound(t *testing.T) {
 		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
 	}
 }
+
+// local_stream:// settings have to point at a stream defined for the host
+func TestConfigHandlerSofiaLocalStreams(t *testing.T) {
+	wd, _ := os.Getwd()
+	templatePath := filepath.Join(wd, "../../templates")
+	sofia.New(filepath.Join(wd, "testdata/localstream"), templatePath)
+	localstream.New(filepath.Join(wd, "testdata/localstream"), templatePath)
+	defer sofia.New(filepath.Join(wd, "../../moduledata"), templatePath)
+	defer localstream.New(filepath.Join(wd, "../../moduledata"), templatePath)
+
+	w := configurationRequest("fs-01", "sofia.conf")
+	expect := `<param name="hold-music" value="local_stream://moh"/>`
+	if !strings.Contains(w.Body.String(), expect) {
+		t.Errorf("defined stream\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+	w = configurationRequest("fs-02", "sofia.conf")
+	if !strings.Contains(w.Body.String(), `status="not found"`) {
+		t.Errorf("undefined stream\n\nExpected not found, got:\n%s\n", w.Body.String())
+	}
+}
+
+func TestConfigHandlerSpandsp(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="spandsp.conf" description="SpanDSP Configuration">
+            <modem-settings>
+                <param name="total-modems" value="0"/>
+                <param name="verbose" value="false"/>
+            </modem-settings>
+            <fax-settings>
+                <param name="use-ecm" value="true"/>
+                <param name="verbose" value="false"/>
+                <param name="ident" value="+1 555 123 0000"/>
+                <param name="header" value="Example Co. Fax"/>
+                <param name="spool-dir" value="$${temp_dir}"/>
+                <param name="file-prefix" value="faxrx"/>
+                <param name="enable-t38" value="true"/>
+                <param name="enable-t38-request" value="true"/>
+            </fax-settings>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "spandsp.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerSpandspNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "spandsp.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerSwitch(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="switch.conf" description="Core Configuration">
+            <cli-keybindings>
+                <key name="1" value="help"/>
+                <key name="2" value="status"/>
+                <key name="3" value="show channels"/>
+                <key name="4" value="show calls"/>
+                <key name="5" value="sofia status"/>
+                <key name="6" value="reloadxml"/>
+            </cli-keybindings>
+            <default-ptimes>
+                <codec name="G723" ptime="60"/>
+            </default-ptimes>
+            <settings>
+                <param name="colorize-console" value="true"/>
+                <param name="dump-cores" value="true"/>
+                <param name="loglevel" value="debug"/>
+                <param name="max-sessions" value="1000"/>
+                <param name="sessions-per-second" value="30"/>
+                <param name="max-db-handles" value="50"/>
+                <param name="db-handle-timeout" value="10"/>
+                <param name="rtp-start-port" value="16384"/>
+                <param name="rtp-end-port" value="32768"/>
+                <param name="rtp-enable-zrtp" value="false"/>
+            </settings>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "switch.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+// free form params can not set a typed setting around its checks
+func TestConfigHandlerSwitchInvalid(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+	wd, _ := os.Getwd()
+	templatePath := filepath.Join(wd, "../../templates")
+	switchconf.New(filepath.Join(wd, "testdata/switch"), templatePath)
+	defer switchconf.New(filepath.Join(wd, "../../moduledata"), templatePath)
+
+	for _, hostname := range []string{"fs-01", "fs-02"} {
+		w := configurationRequest(hostname, "switch.conf")
+		if w.Body.String() != expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", hostname, expect, w.Body.String())
+		}
+	}
+}
+
+func TestConfigHandlerSwitchNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "switch.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerSyslog(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="syslog.conf" description="Syslog Logger">
+            <settings>
+                <param name="facility" value="local0"/>
+                <param name="ident" value="freeswitch"/>
+                <param name="loglevel" value="notice"/>
+                <param name="uuid" value="true"/>
+            </settings>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "syslog.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerSyslogNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "syslog.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+// hosts override single values of the group they inherit from and get the rest of it, the groups
+// themselves are never served
+func TestConfigHandlerInherited(t *testing.T) {
+	notFound := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+	consoleConf := func(loglevel string) string {
+		return `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="console.conf" description="Console Logger">
+            <mappings>
+                <map name="all" value="console,info,notice,warning,err,crit,alert"/>
+            </mappings>
+            <settings>
+                <param name="colorize" value="true"/>
+                <param name="loglevel" value="` + loglevel + `"/>
+            </settings>
+        </configuration>
+    </section>
+</document>
+`
+	}
+	logfileConf := func(maximumRotate string) string {
+		return `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="logfile.conf" description="File Logging">
+            <settings>
+                <param name="rotate-on-hup" value="true"/>
+            </settings>
+            <profiles>
+                <profile name="default">
+                    <settings>
+                        <param name="logfile" value="/var/log/freeswitch/freeswitch.log"/>
+                        <param name="rollover" value="10485760"/>
+                        <param name="maximum-rotate" value="` + maximumRotate + `"/>
+                        <param name="uuid" value="true"/>
+                    </settings>
+                    <mappings>
+                        <map name="all" value="console,info,notice,warning,err,crit,alert"/>
+                    </mappings>
+                </profile>
+            </profiles>
+        </configuration>
+    </section>
+</document>
+`
+	}
+	syslogConf := func(loglevel string) string {
+		return `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="syslog.conf" description="Syslog Logger">
+            <settings>
+                <param name="facility" value="local0"/>
+                <param name="ident" value="freeswitch"/>
+                <param name="loglevel" value="` + loglevel + `"/>
+                <param name="uuid" value="true"/>
+            </settings>
+        </configuration>
+    </section>
+</document>
+`
+	}
+	_, restore := moduleDataFixture(t, []string{"testdata/inherit/console.json", "testdata/inherit/logfile.json", "testdata/inherit/syslog.json"}, console.New, logfile.New, syslog.New)
+	defer restore()
+
+	tests := []struct {
+		hostname string
+		name     string
+		expect   string
+	}{
+		{"fs-01", "console.conf", consoleConf("debug")},
+		{"fs-02", "console.conf", consoleConf("info")},
+		{"group:default", "console.conf", notFound},
+		{"fs-01", "logfile.conf", logfileConf("8")},
+		{"fs-02", "logfile.conf", logfileConf("32")},
+		{"group:default", "logfile.conf", notFound},
+		{"fs-01", "syslog.conf", syslogConf("err")},
+		{"fs-02", "syslog.conf", syslogConf("warning")},
+		{"group:default", "syslog.conf", notFound},
+	}
+	for _, tt := range tests {
+		w := configurationRequest(tt.hostname, tt.name)
+		if w.Body.String() != tt.expect {
+			t.Errorf("%s %s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.hostname, tt.name, tt.expect, w.Body.String())
+		}
+	}
+}
+
+func TestConfigHandlerVerto(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="configuration" description="FreeSWITCH Configuration">
+        <configuration name="verto.conf" description="HTML5 Verto Endpoint">
+            <settings>
+                <param name="debug" value="0"/>
+            </settings>
+            <profiles>
+                <profile name="default-v4">
+                    <param name="bind-local" value="$${local_ip_v4}:8081"/>
+                    <param name="bind-local" value="$${local_ip_v4}:8082" secure="true"/>
+                    <param name="userauth" value="true"/>
+                    <param name="blind-reg" value="false"/>
+                    <param name="mcast-ip" value="224.1.1.1"/>
+                    <param name="mcast-port" value="1337"/>
+                    <param name="rtp-ip" value="$${local_ip_v4}"/>
+                    <param name="local-network" value="localnet.auto"/>
+                    <param name="apply-candidate-acl" value="lan"/>
+                    <param name="apply-candidate-acl" value="wan_v4.auto"/>
+                    <param name="force-register-domain" value="$${domain}"/>
+                    <param name="secure-combined" value="$${certs_dir}/wss.pem"/>
+                    <param name="secure-chain" value="$${certs_dir}/wss.pem"/>
+                    <param name="timer-name" value="soft"/>
+                    <vhosts>
+                        <vhost domain="webrtc.example.com">
+                            <param name="alias" value="verto.example.com"/>
+                            <param name="root" value="/usr/share/freeswitch/htdocs"/>
+                            <param name="index" value="index.html"/>
+                        </vhost>
+                    </vhosts>
+                </profile>
+            </profiles>
+        </configuration>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "verto.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestConfigHandlerVertoNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-02")
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", "verto.conf")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
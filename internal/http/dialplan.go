@@ -0,0 +1,34 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/dialplan"
+)
+
+var (
+	dialplans dialplanHandler
+)
+
+type dialplanHandler struct{}
+
+func (dialplanHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	cr := requestForm(r.PostForm)
+
+	req := dialplan.Request{
+		Hostname:          cr.Get("hostname"),
+		Context:           cr.Get("Caller-Context"),
+		DestinationNumber: cr.Get("Caller-Destination-Number"),
+		CallerIDNumber:    cr.Get("Caller-Caller-ID-Number"),
+		PrivacyHideNumber: cr.Get("Caller-Privacy-Hide-Number") == "true",
+	}
+	err := dialplan.Handler(r.Context(), req, w)
+	if err != nil {
+		rlog.Errorf("could not load dialplan [%s]", err.Error())
+		notFound(w)
+	}
+	return
+}
@@ -0,0 +1,355 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/dialplan"
+)
+
+func dialplanRequest(params map[string]string) *httptest.ResponseRecorder {
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "dialplan")
+	form.Add("Caller-Context", "public")
+	form.Add("Caller-Caller-ID-Number", "+15555550123")
+	for k, v := range params {
+		form.Set(k, v)
+	}
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	dialplans.Handler(w, r)
+	return w
+}
+
+func TestDialplanHandlerDIDs(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		expect string
+	}{
+		{"extension e164", "+15555550100", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="did_15555550100">
+                <condition field="destination_number" expression="^\+15555550100$">
+                    <action application="set" data="e164_destination_number=+15555550100"/>
+                    <action application="set" data="domain_name=example.com"/>
+                    <action application="transfer" data="1000 XML default"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"queue national", "5555550101", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="did_15555550101">
+                <condition field="destination_number" expression="^5555550101$">
+                    <action application="set" data="e164_destination_number=+15555550101"/>
+                    <action application="set" data="domain_name=example.com"/>
+                    <action application="answer"/>
+                    <action application="fifo" data="support@$${domain} in"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"conference with country code", "15555550102", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="did_15555550102">
+                <condition field="destination_number" expression="^15555550102$">
+                    <action application="set" data="e164_destination_number=+15555550102"/>
+                    <action application="set" data="domain_name=example.com"/>
+                    <action application="answer"/>
+                    <action application="conference" data="allhands@default"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"ivr international prefix", "01115555550103", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="did_15555550103">
+                <condition field="destination_number" expression="^01115555550103$">
+                    <action application="set" data="e164_destination_number=+15555550103"/>
+                    <action application="set" data="domain_name=example.com"/>
+                    <action application="answer"/>
+                    <action application="ivr" data="main_menu"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"external", "+15555550104", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="did_15555550104">
+                <condition field="destination_number" expression="^\+15555550104$">
+                    <action application="set" data="e164_destination_number=+15555550104"/>
+                    <action application="set" data="domain_name=example.com"/>
+                    <action application="bridge" data="sofia/gateway/carrier-01/+15555550199"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"scheduled queue", "+15555550106", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="did_15555550106_schedule" continue="true">
+                <condition field="destination_number" expression="^\+15555550106$">
+                    <action application="set" data="timezone=America/New_York" inline="true"/>
+                    <action application="set" data="schedule_open=false" inline="true"/>
+                </condition>
+            </extension>
+            <extension name="did_15555550106_open_1" continue="true">
+                <condition field="destination_number" expression="^\+15555550106$"/>
+                <condition wday="2-6" time-of-day="09:00-17:00">
+                    <action application="set" data="schedule_open=true" inline="true"/>
+                </condition>
+            </extension>
+            <extension name="did_15555550106_open_2" continue="true">
+                <condition field="destination_number" expression="^\+15555550106$"/>
+                <condition wday="7" time-of-day="10:00-14:00">
+                    <action application="set" data="schedule_open=true" inline="true"/>
+                </condition>
+            </extension>
+            <extension name="did_15555550106_holiday_1" continue="true">
+                <condition field="destination_number" expression="^\+15555550106$"/>
+                <condition date-time="2026-12-25 00:00~2026-12-26 00:00">
+                    <action application="set" data="schedule_open=false" inline="true"/>
+                </condition>
+            </extension>
+            <extension name="did_15555550106_holiday_2" continue="true">
+                <condition field="destination_number" expression="^\+15555550106$"/>
+                <condition date-time="2026-12-31 12:00~2027-01-01 00:00">
+                    <action application="set" data="schedule_open=false" inline="true"/>
+                </condition>
+            </extension>
+            <extension name="did_15555550106">
+                <condition field="destination_number" expression="^\+15555550106$"/>
+                <condition field="${schedule_open}" expression="^true$">
+                    <action application="set" data="e164_destination_number=+15555550106"/>
+                    <action application="set" data="domain_name=example.com"/>
+                    <action application="answer"/>
+                    <action application="fifo" data="support@$${domain} in"/>
+                    <anti-action application="set" data="e164_destination_number=+15555550106"/>
+                    <anti-action application="set" data="domain_name=example.com"/>
+                    <anti-action application="transfer" data="support_voicemail XML default"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+	}
+	for _, tt := range tests {
+		w := dialplanRequest(map[string]string{"Caller-Destination-Number": tt.number})
+		if w.Body.String() != tt.expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, tt.expect, w.Body.String())
+		}
+	}
+}
+
+func TestDialplanHandlerNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+	tests := []map[string]string{
+		// not in the did table
+		{"Caller-Destination-Number": "+15555550199"},
+		// not a number the numbering plan can normalize
+		{"Caller-Destination-Number": "1000"},
+		{"Caller-Destination-Number": "+15555550100", "Caller-Context": "internal"},
+		// internal extensions are left to the static dialplan
+		{"Caller-Destination-Number": "1000", "Caller-Context": "default"},
+		// numbers dialed outside the outbound context are not routed out
+		{"Caller-Destination-Number": "5555550123", "Caller-Context": "default"},
+		{"Caller-Destination-Number": "+15555550100", "hostname": "fs-02"},
+	}
+	for _, params := range tests {
+		w := dialplanRequest(params)
+		if w.Body.String() != expect {
+			t.Errorf("%v\n\nExpected:\n%s\n\nGot:\n%s\n", params, expect, w.Body.String())
+		}
+	}
+}
+
+// dids of testdata/dialplan that do not match the rest of the module data give not found
+func TestDialplanHandlerInvalid(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+	wd, _ := os.Getwd()
+	templatePath := filepath.Join(wd, "../../templates")
+	dialplan.New(filepath.Join(wd, "testdata/dialplan"), templatePath)
+	defer dialplan.New(filepath.Join(wd, "../../moduledata"), templatePath)
+
+	tests := []map[string]string{
+		// domain not in the directory
+		{"Caller-Destination-Number": "+15555550105"},
+		// schedule not found
+		{"Caller-Destination-Number": "+15555550107"},
+		// gateway not defined in sofia.json
+		{"Caller-Destination-Number": "+442071234567", "Caller-Context": "outbound", "hostname": "fs-03"},
+		// routes without a context do not claim the context extensions are dialed in
+		{"Caller-Destination-Number": "+442071234567", "Caller-Context": "default", "hostname": "fs-04"},
+	}
+	for _, params := range tests {
+		w := dialplanRequest(params)
+		if w.Body.String() != expect {
+			t.Errorf("%v\n\nExpected:\n%s\n\nGot:\n%s\n", params, expect, w.Body.String())
+		}
+	}
+}
+
+func TestDialplanHandlerOutbound(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		expect string
+	}{
+		{"longest prefix with failover", "5555550123", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="outbound">
+            <extension name="outbound_15555550123">
+                <condition field="destination_number" expression="^5555550123$">
+                    <action application="set" data="e164_destination_number=+15555550123"/>
+                    <action application="set" data="outbound_route=+1555"/>
+                    <action application="set" data="continue_on_fail=true"/>
+                    <action application="set" data="hangup_after_bridge=true"/>
+                    <action application="bridge" data="sofia/gateway/proxy-01.local/5555550123|sofia/gateway/proxy-02.local/01115555550123"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"default route", "011442071234567", `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="outbound">
+            <extension name="outbound_442071234567">
+                <condition field="destination_number" expression="^011442071234567$">
+                    <action application="set" data="e164_destination_number=+442071234567"/>
+                    <action application="set" data="outbound_route=+"/>
+                    <action application="set" data="continue_on_fail=true"/>
+                    <action application="set" data="hangup_after_bridge=true"/>
+                    <action application="bridge" data="sofia/gateway/proxy-01.local/442071234567"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+	}
+	for _, tt := range tests {
+		w := dialplanRequest(map[string]string{"Caller-Context": "outbound", "Caller-Destination-Number": tt.number})
+		if w.Body.String() != tt.expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, tt.expect, w.Body.String())
+		}
+	}
+}
+
+func TestDialplanHandlerBlocklists(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		expect string
+	}{
+		{"global number", map[string]string{"Caller-Caller-ID-Number": "5555550666"}, `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="blocked_15555550100">
+                <condition field="destination_number" expression="^\+15555550100$"/>
+                <condition field="caller_id_number" expression="^5555550666$">
+                    <action application="set" data="blocked_by=global"/>
+                    <action application="hangup" data="CALL_REJECTED"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"global prefix", map[string]string{"Caller-Caller-ID-Number": "+19005550100"}, `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="blocked_15555550100">
+                <condition field="destination_number" expression="^\+15555550100$"/>
+                <condition field="caller_id_number" expression="^\+19005550100$">
+                    <action application="set" data="blocked_by=global"/>
+                    <action application="hangup" data="CALL_REJECTED"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+		{"domain anonymous", map[string]string{"Caller-Caller-ID-Number": "anonymous"}, `<document type="freeswitch/xml">
+    <section name="dialplan" description="RE Dial Plan For FreeSwitch">
+        <context name="public">
+            <extension name="blocked_15555550100">
+                <condition field="destination_number" expression="^\+15555550100$"/>
+                <condition field="caller_id_number" expression="^anonymous$">
+                    <action application="set" data="blocked_by=domain"/>
+                    <action application="pre_answer"/>
+                    <action application="playback" data="ivr/ivr-call_rejected.wav"/>
+                    <action application="hangup" data="USER_BUSY"/>
+                </condition>
+            </extension>
+        </context>
+    </section>
+</document>
+`},
+	}
+	for _, tt := range tests {
+		params := map[string]string{"Caller-Destination-Number": "+15555550100"}
+		for k, v := range tt.params {
+			params[k] = v
+		}
+		w := dialplanRequest(params)
+		if w.Body.String() != tt.expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, tt.expect, w.Body.String())
+		}
+	}
+
+	// callers hiding their number are anonymous, others are routed
+	allowed := []map[string]string{
+		{"Caller-Caller-ID-Number": "+15555550199"},
+		{"Caller-Caller-ID-Number": "+19015550100"},
+	}
+	for _, params := range allowed {
+		params["Caller-Destination-Number"] = "+15555550100"
+		w := dialplanRequest(params)
+		if !strings.Contains(w.Body.String(), `<extension name="did_15555550100">`) {
+			t.Errorf("%v\n\nExpected the did extension, got:\n%s\n", params, w.Body.String())
+		}
+	}
+	w := dialplanRequest(map[string]string{"Caller-Destination-Number": "+15555550100", "Caller-Privacy-Hide-Number": "true"})
+	if !strings.Contains(w.Body.String(), `data="blocked_by=domain"`) {
+		t.Errorf("Expected a hidden number to be blocked, got:\n%s\n", w.Body.String())
+	}
+}
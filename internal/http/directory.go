@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+)
+
+var (
+	directories directoryHandler
+)
+
+type directoryHandler struct{}
+
+func (directoryHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	cr := requestForm(r.PostForm)
+
+	req := directory.Request{
+		Hostname: cr.Get("hostname"),
+		Domain:   cr.Get("domain"),
+		User:     cr.Get("user"),
+		Group:    cr.Get("group_name"),
+		Action:   cr.Get("action"),
+		Purpose:  cr.Get("purpose"),
+	}
+	if req.Domain == "" {
+		req.Domain = cr.Get("key_value")
+	}
+	err := directory.Handler(r.Context(), req, w)
+	if err != nil {
+		rlog.Errorf("could not load directory [%s]", err.Error())
+		notFound(w)
+	}
+	return
+}
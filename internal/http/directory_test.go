@@ -0,0 +1,223 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func directoryRequest(params map[string]string) *httptest.ResponseRecorder {
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "directory")
+	form.Add("tag_name", "domain")
+	form.Add("key_name", "name")
+	form.Add("key_value", "example.com")
+	form.Add("domain", "example.com")
+	for k, v := range params {
+		form.Set(k, v)
+	}
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	directories.Handler(w, r)
+	return w
+}
+
+func TestDirectoryHandlerActions(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		expect string
+	}{
+		{"sip_auth password", map[string]string{"action": "sip_auth", "user": "1000"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <variables>
+                <variable name="user_context" value="default"/>
+            </variables>
+            <users>
+                <user id="1000">
+                    <params>
+                        <param name="a1-hash" value="b016704db5cfd8fbfe9e45418ae5c6e9"/>
+                        <param name="auth-acl" value="lan"/>
+                    </params>
+                    <variables>
+                        <variable name="callgroup" value="sales"/>
+                        <variable name="effective_caller_id_number" value="1000"/>
+                        <variable name="effective_caller_id_name" value="Alice"/>
+                    </variables>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`},
+		{"sip_auth a1-hash", map[string]string{"action": "sip_auth", "user": "1001"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <variables>
+                <variable name="user_context" value="default"/>
+            </variables>
+            <users>
+                <user id="1001">
+                    <params>
+                        <param name="a1-hash" value="dc3e90b36655cfe735babef8734bd63b"/>
+                    </params>
+                    <variables>
+                        <variable name="callgroup" value="sales"/>
+                        <variable name="effective_caller_id_number" value="5000"/>
+                        <variable name="effective_caller_id_name" value="Bob"/>
+                    </variables>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`},
+		{"user_call domain dial-string", map[string]string{"action": "user_call", "user": "1000"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <params>
+                <param name="dial-string" value="{^^:sip_invite_domain=${dialed_domain}:presence_id=${dialed_user}@${dialed_domain}}${sofia_contact(*/${dialed_user}@${dialed_domain})}"/>
+            </params>
+            <variables>
+                <variable name="user_context" value="default"/>
+            </variables>
+            <users>
+                <user id="1000">
+                    <variables>
+                        <variable name="callgroup" value="sales"/>
+                        <variable name="effective_caller_id_number" value="1000"/>
+                        <variable name="effective_caller_id_name" value="Alice"/>
+                    </variables>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`},
+		{"user_call user dial-string", map[string]string{"action": "user_call", "user": "1001"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <variables>
+                <variable name="user_context" value="default"/>
+            </variables>
+            <users>
+                <user id="1001">
+                    <params>
+                        <param name="dial-string" value="{presence_id=1001@example.com}${sofia_contact(1001@example.com)},user/1001-mobile@example.com"/>
+                    </params>
+                    <variables>
+                        <variable name="callgroup" value="sales"/>
+                        <variable name="effective_caller_id_number" value="5000"/>
+                        <variable name="effective_caller_id_name" value="Bob"/>
+                    </variables>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`},
+		{"group_call", map[string]string{"action": "group_call", "group_name": "sales"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <params>
+                <param name="dial-string" value="{^^:sip_invite_domain=${dialed_domain}:presence_id=${dialed_user}@${dialed_domain}}${sofia_contact(*/${dialed_user}@${dialed_domain})}"/>
+            </params>
+            <groups>
+                <group name="sales">
+                    <users>
+                        <user id="1000" type="pointer"/>
+                        <user id="1001" type="pointer"/>
+                    </users>
+                </group>
+            </groups>
+        </domain>
+    </section>
+</document>
+`},
+		{"message-count", map[string]string{"action": "message-count", "user": "1000"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <users>
+                <user id="1000">
+                    <params>
+                        <param name="vm-password" value="1000"/>
+                        <param name="vm-mailto" value="alice@example.com"/>
+                    </params>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`},
+		{"reverse-auth-lookup", map[string]string{"action": "reverse-auth-lookup", "user": "carrier"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <users>
+                <user id="carrier">
+                    <params>
+                        <param name="reverse-auth-user" value="carrier"/>
+                        <param name="reverse-auth-pass" value="carrier-secret"/>
+                    </params>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`},
+		{"gateways", map[string]string{"purpose": "gateways"}, `<document type="freeswitch/xml">
+    <section name="directory">
+        <domain name="example.com">
+            <users>
+                <user id="carrier">
+                    <gateways>
+                        <gateway name="carrier-01">
+                            <param name="proxy" value="sip.carrier.example.net"/>
+                            <param name="register" value="false"/>
+                        </gateway>
+                    </gateways>
+                </user>
+            </users>
+        </domain>
+    </section>
+</document>
+`},
+	}
+	for _, tt := range tests {
+		w := directoryRequest(tt.params)
+		if w.Body.String() != tt.expect {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, tt.expect, w.Body.String())
+		}
+	}
+}
+
+func TestDirectoryHandlerNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+	tests := []map[string]string{
+		{"action": "sip_auth", "user": "2000"},
+		{"action": "sip_auth", "user": "carrier"},
+		{"action": "sip_auth", "user": "1002"},
+		{"action": "reverse-auth-lookup", "user": "1000"},
+		{"action": "group_call", "group_name": "support"},
+		{"action": "sip_auth", "user": "1000", "domain": "example.net"},
+		{"action": "sip_auth", "user": "1000", "hostname": "fs-02"},
+	}
+	for _, params := range tests {
+		w := directoryRequest(params)
+		if w.Body.String() != expect {
+			t.Errorf("%v\n\nExpected:\n%s\n\nGot:\n%s\n", params, expect, w.Body.String())
+		}
+	}
+}
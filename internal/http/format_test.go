@@ -0,0 +1,103 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/acl"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/sofia"
+)
+
+// moduleDataFixture copies `files` to a temp dir and points the modules and sections set up by
+// `setups` at it, so tests can change module data without touching the fixtures of the other tests.
+// The returned func points them back at the module data of the repo and removes the dir
+func moduleDataFixture(t *testing.T, files []string, setups ...func(m string, t string) error) (string, func()) {
+	wd, _ := os.Getwd()
+	moduleData := filepath.Join(wd, "../../moduledata")
+	templatePath := filepath.Join(wd, "../../templates")
+	dir, err := ioutil.TempDir("", "moduledata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, filepath.Base(f)), b, 0600); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	for _, setup := range setups {
+		setup(dir, templatePath)
+	}
+	return dir, func() {
+		for _, setup := range setups {
+			setup(moduleData, templatePath)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func configurationRequest(hostname string, name string) *httptest.ResponseRecorder {
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", hostname)
+	form.Add("section", "configuration")
+	form.Add("tag_name", "configuration")
+	form.Add("key_name", "name")
+	form.Add("key_value", name)
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	configuration.Handler(w, r)
+	return w
+}
+
+// module data in yaml and toml has to render the same xml as the json in moduledata
+func TestConfigHandlerFormats(t *testing.T) {
+	wd, _ := os.Getwd()
+	moduleData := filepath.Join(wd, "../../moduledata")
+	templatePath := filepath.Join(wd, "../../templates")
+
+	tests := []struct {
+		name  string
+		dir   string
+		setup func(m string, t string) error
+	}{
+		{"sofia.conf", "testdata/yaml", sofia.New},
+		{"acl.conf", "testdata/toml", acl.New},
+	}
+	for _, tt := range tests {
+		expect := configurationRequest("fs-01", tt.name).Body.String()
+		tt.setup(filepath.Join(wd, tt.dir), templatePath)
+		got := configurationRequest("fs-01", tt.name).Body.String()
+		tt.setup(moduleData, templatePath)
+		if !strings.Contains(expect, `<configuration name="`+tt.name+`"`) {
+			t.Fatalf("%s not rendered from json:\n%s\n", tt.name, expect)
+		}
+		if got != expect {
+			t.Errorf("%s from %s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, tt.dir, expect, got)
+		}
+	}
+}
+
+// the same module data in two formats is ambiguous and not served
+func TestConfigHandlerFormatsAmbiguous(t *testing.T) {
+	_, restore := moduleDataFixture(t, []string{"../../moduledata/acl.json", "testdata/toml/acl.toml"}, acl.New)
+	defer restore()
+
+	w := configurationRequest("fs-01", "acl.conf")
+	if !strings.Contains(w.Body.String(), `status="not found"`) {
+		t.Errorf("Expected not found, got:\n%s\n", w.Body.String())
+	}
+}
@@ -0,0 +1,208 @@
+package http
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/acl"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/amqp"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/distributor"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/fifo"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/localstream"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/sofia"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/dialplan"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/moduledata"
+)
+
+var (
+	// modules and sections pointed at the split module data, with the ones they check against
+	hostLayoutModules = []func(m string, t string) error{
+		acl.New,
+		amqp.New,
+		distributor.New,
+		fifo.New,
+		localstream.New,
+		modules.New,
+		sofia.New,
+		dialplan.New,
+		directory.New,
+	}
+)
+
+// hostModuleData copies the module data to a temp dir, splits it into host files and points the
+// modules at it. The returned func restores them
+func hostModuleData(t *testing.T) (string, func()) {
+	files, err := filepath.Glob("../../moduledata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, restore := moduleDataFixture(t, files, hostLayoutModules...)
+	if _, err = moduledata.Split(dir, false); err != nil {
+		restore()
+		t.Fatal(err)
+	}
+	return dir, restore
+}
+
+func TestSplitModuleData(t *testing.T) {
+	dir, restore := hostModuleData(t)
+	defer restore()
+
+	tests := []struct {
+		file   string
+		shared bool
+	}{
+		// only fs-01 in it, nothing left to share
+		{"acl.json", false},
+		// keeps the group fs-01 inherits from
+		{"amqp.json", true},
+		// roles are shared by every host
+		{"modules.json", true},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(filepath.Join(dir, tt.file))
+		if tt.shared != (err == nil) {
+			t.Errorf("%s shared file kept [%t], expected [%t]", tt.file, err == nil, tt.shared)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hosts/fs-01/acl.json")); err != nil {
+		t.Errorf("Expected acl host file, got %s", err.Error())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hosts/group:us-east")); err == nil {
+		t.Errorf("Expected groups to stay in the shared file")
+	}
+	moves, err := moduledata.Split(dir, false)
+	if err != nil || len(moves) != 0 {
+		t.Errorf("Expected nothing left to split, got %d moves %v", len(moves), err)
+	}
+
+	// a host back in the shared file is never split over its host file
+	if err = ioutil.WriteFile(filepath.Join(dir, "acl.json"), []byte(`{"fs-01": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = moduledata.Split(dir, false); err == nil {
+		t.Errorf("Expected existing host file to fail the split")
+	}
+	if err = os.Remove(filepath.Join(dir, "acl.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	// yaml and toml are never rewritten, hosts in them fail the split
+	if err = ioutil.WriteFile(filepath.Join(dir, "conference.yaml"), []byte("fs-02:\n  settings: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = moduledata.Split(dir, false); err == nil {
+		t.Errorf("Expected yaml host entries to fail the split")
+	}
+	if _, err = os.Stat(filepath.Join(dir, "hosts/fs-02")); err == nil {
+		t.Errorf("Expected nothing to be split from yaml")
+	}
+}
+
+// a host moved to its own files has to get the same xml it got from the shared files
+func TestHostLayoutHandlers(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func() string
+	}{
+		{"acl", func() string { return configurationRequest("fs-01", "acl.conf").Body.String() }},
+		{"amqp inherited", func() string { return configurationRequest("fs-01", "amqp.conf").Body.String() }},
+		{"distributor", func() string { return configurationRequest("fs-01", "distributor.conf").Body.String() }},
+		{"modules", func() string { return configurationRequest("fs-01", "modules.conf").Body.String() }},
+		{"sofia", func() string { return configurationRequest("fs-01", "sofia.conf").Body.String() }},
+		{"directory", func() string {
+			return directoryRequest(map[string]string{"action": "sip_auth", "user": "1000"}).Body.String()
+		}},
+		{"dialplan", func() string {
+			return dialplanRequest(map[string]string{"Caller-Destination-Number": "+15555550101"}).Body.String()
+		}},
+	}
+	expect := map[string]string{}
+	for _, tt := range tests {
+		expect[tt.name] = tt.request()
+		if strings.Contains(expect[tt.name], `status="not found"`) {
+			t.Fatalf("%s not found in shared module data", tt.name)
+		}
+	}
+	_, restore := hostModuleData(t)
+	defer restore()
+	for _, tt := range tests {
+		if got := tt.request(); got != expect[tt.name] {
+			t.Errorf("%s\n\nExpected:\n%s\n\nGot:\n%s\n", tt.name, expect[tt.name], got)
+		}
+	}
+}
+
+// host files are reloaded on their own and a hostname is never used as a path outside hosts/
+func TestHostLayoutChanges(t *testing.T) {
+	dir, restore := hostModuleData(t)
+	defer restore()
+
+	f := filepath.Join(dir, "hosts/fs-01/acl.json")
+	b, err := ioutil.ReadFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configurationRequest("fs-01", "acl.conf")
+	if err = ioutil.WriteFile(f, []byte(strings.Replace(string(b), `"lan"`, `"office-lan"`, 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w := configurationRequest("fs-01", "acl.conf")
+	if !strings.Contains(w.Body.String(), `<list name="office-lan"`) {
+		t.Errorf("Expected changed host file to be reloaded, got:\n%s\n", w.Body.String())
+	}
+
+	// a removed host file is not served from the cache
+	if err = os.Remove(f); err != nil {
+		t.Fatal(err)
+	}
+	w = configurationRequest("fs-01", "acl.conf")
+	if !strings.Contains(w.Body.String(), `status="not found"`) {
+		t.Errorf("Expected removed host file to be not found, got:\n%s\n", w.Body.String())
+	}
+
+	w = configurationRequest("../hosts/fs-01", "acl.conf")
+	if !strings.Contains(w.Body.String(), `status="not found"`) {
+		t.Errorf("Expected not found, got:\n%s\n", w.Body.String())
+	}
+}
+
+// admin changes to a host with its own file are written to that file
+func TestHostLayoutAdminWrites(t *testing.T) {
+	dir, restore := hostModuleData(t)
+	defer restore()
+
+	if err := directory.SetPassword("fs-01", "example.com", "1002", "new-secret"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "hosts/fs-01/directory.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := directory.A1Hash("1002", "example.com", "new-secret")
+	if !strings.Contains(string(b), hash) {
+		t.Errorf("Expected a1-hash [%s] in host file, got:\n%s\n", hash, string(b))
+	}
+	if _, err = os.Stat(filepath.Join(dir, "directory.json")); err == nil {
+		t.Errorf("Expected no shared directory file to be written")
+	}
+
+	if err = dialplan.SetSchedule("fs-01", dialplan.Schedule{Name: "weekend", Timezone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := dialplan.Schedules("fs-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) == 0 || s[len(s)-1].Name != "weekend" {
+		t.Errorf("Expected weekend schedule, got %v", s)
+	}
+	if err = dialplan.DeleteSchedule("fs-01", "weekend"); err != nil {
+		t.Error(err)
+	}
+}
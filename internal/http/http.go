@@ -9,8 +9,26 @@ import (
 	"goji.io/pat"
 
 	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/acl"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/amqp"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/console"
 	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/distributor"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/eventsocket"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/fifo"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/generic"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/hiredis"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/localstream"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/logfile"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/modules"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/nibblebill"
 	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/sofia"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/spandsp"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/switchconf"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/syslog"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/verto"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/chatplan"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/dialplan"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/phrases"
 )
 
 const (
@@ -26,23 +44,120 @@ var (
 
 type httpHandler struct {}
 
-func New(root *goji.Mux, httpAddress string, moduleDataDirectoryCfg string, templatePath string) error {
+func New(root *goji.Mux, httpAddress string, moduleDataDirectoryCfg string, templatePath string, genericModules []generic.Conf) error {
 	// setup freeswitch modules
 	err := acl.New(moduleDataDirectoryCfg, templatePath)
 	if err != nil {
 		return err
 	}
 	rlog.Info("setup acl module")
+	err = amqp.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup amqp module")
+	err = console.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup console module")
 	err = distributor.New(moduleDataDirectoryCfg, templatePath)
 	if err != nil {
 		return err
 	}
 	rlog.Info("setup distributor module")
+	err = eventsocket.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup event_socket module")
+	err = fifo.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup fifo module")
+	err = hiredis.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup hiredis module")
+	err = localstream.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup local_stream module")
+	err = logfile.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup logfile module")
+	err = modules.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup modules module")
+	err = nibblebill.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup nibblebill module")
 	err = sofia.New(moduleDataDirectoryCfg, templatePath)
 	if err != nil {
 		return err
 	}
 	rlog.Info("setup sofia module")
+	err = spandsp.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup spandsp module")
+	err = switchconf.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup switch module")
+	err = syslog.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup syslog module")
+	err = verto.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup verto module")
+
+	err = checkGenericModules(genericModules)
+	if err != nil {
+		return err
+	}
+	err = generic.New(moduleDataDirectoryCfg, templatePath, genericModules)
+	if err != nil {
+		return err
+	}
+	rlog.Infof("setup [%d] generic modules", len(genericModules))
+
+	// setup freeswitch sections
+	err = chatplan.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup chatplan section")
+	err = dialplan.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup dialplan section")
+	err = directory.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup directory section")
+	err = phrases.New(moduleDataDirectoryCfg, templatePath)
+	if err != nil {
+		return err
+	}
+	rlog.Info("setup phrases section")
 
 	// setup not found template
 	notFoundTemplatePath = filepath.Join(templatePath, notFoundTemplate)
@@ -59,4 +174,29 @@ func New(root *goji.Mux, httpAddress string, moduleDataDirectoryCfg string, temp
 func registerMux(m *goji.Mux) {
 	m.HandleFunc(pat.Post("/configuration"), configuration.Handler)
 	rlog.Debug("registered configuration endpoint")
+	m.HandleFunc(pat.Post("/cdr"), cdrs.Post)
+	m.HandleFunc(pat.Get("/cdr"), cdrs.Get)
+	rlog.Debug("registered cdr endpoint")
+	m.HandleFunc(pat.Post("/phrases"), languages.Phrases)
+	m.HandleFunc(pat.Post("/languages"), languages.Languages)
+	rlog.Debug("registered phrases and languages endpoints")
+	m.HandleFunc(pat.Post("/chatplan"), chatplans.Handler)
+	rlog.Debug("registered chatplan endpoint")
+	m.HandleFunc(pat.Post("/dialplan"), dialplans.Handler)
+	rlog.Debug("registered dialplan endpoint")
+	m.HandleFunc(pat.Post("/directory"), directories.Handler)
+	rlog.Debug("registered directory endpoint")
+	m.HandleFunc(pat.Post("/admin/directory/password"), admins.SetPassword)
+	m.HandleFunc(pat.Post("/admin/directory/rename"), admins.RenameDomain)
+	m.HandleFunc(pat.Post("/admin/directory/import"), admins.Import)
+	m.HandleFunc(pat.Get("/admin/directory/effective"), admins.Effective)
+	rlog.Debug("registered admin directory endpoints")
+	m.HandleFunc(pat.Get("/admin/dialplan/schedules"), admins.Schedules)
+	m.HandleFunc(pat.Post("/admin/dialplan/schedules"), admins.SetSchedule)
+	m.HandleFunc(pat.Delete("/admin/dialplan/schedules"), admins.DeleteSchedule)
+	m.HandleFunc(pat.Get("/admin/dialplan/blocklist"), admins.Blocklist)
+	m.HandleFunc(pat.Post("/admin/dialplan/blocklist"), admins.SetBlocklist)
+	m.HandleFunc(pat.Post("/admin/dialplan/blocklist/entries"), admins.Block)
+	m.HandleFunc(pat.Delete("/admin/dialplan/blocklist/entries"), admins.Unblock)
+	rlog.Debug("registered admin dialplan endpoints")
 }
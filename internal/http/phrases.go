@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/romana/rlog"
+
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/phrases"
+)
+
+var (
+	languages languagesHandler
+)
+
+type languagesHandler struct{}
+
+// language returns the requested language, FreeSWITCH sends phrase lookups without a key so the
+// language comes in the `lang` param
+func (c requestForm) language() string {
+	if l := c.Get("lang"); l != "" {
+		return l
+	}
+	return c.Get("key_value")
+}
+
+func (languagesHandler) Phrases(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	cr := requestForm(r.PostForm)
+
+	err := phrases.PhrasesHandler(r.Context(), cr.language(), cr.Get("hostname"), w)
+	if err != nil {
+		rlog.Errorf("could not load phrases [%s]", err.Error())
+		notFound(w)
+	}
+	return
+}
+
+func (languagesHandler) Languages(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	cr := requestForm(r.PostForm)
+
+	err := phrases.LanguagesHandler(r.Context(), cr.language(), cr.Get("hostname"), w)
+	if err != nil {
+		rlog.Errorf("could not load language [%s]", err.Error())
+		notFound(w)
+	}
+	return
+}
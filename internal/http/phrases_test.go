@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPhrasesHandler(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="phrases" description="Speech Phrase Management">
+        <macros>
+            <language name="es" say-module="es" sound-prefix="$${sounds_dir}/es/mx/maria">
+                <macro name="ivr_main_menu">
+                    <input pattern="^open$">
+                        <match>
+                            <action function="play-file" data="ivr/main-menu-open.wav"/>
+                        </match>
+                    </input>
+                    <input pattern="^closed$">
+                        <match>
+                            <action function="play-file" data="ivr/main-menu-closed.wav"/>
+                        </match>
+                    </input>
+                </macro>
+            </language>
+        </macros>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "phrases")
+	form.Add("lang", "es")
+	form.Add("macro_name", "ivr_main_menu")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	languages.Phrases(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestLanguagesHandler(t *testing.T) {
+	expect := `<document type="freeswitch/xml">
+    <section name="languages" description="Language Management">
+        <language name="en" say-module="en" sound-prefix="$${sounds_dir}/en/us/callie">
+            <phrases>
+                <macros>
+                    <macro name="ivr_main_menu">
+                        <input pattern="^open$">
+                            <match>
+                                <action function="play-file" data="ivr/main-menu-open.wav"/>
+                            </match>
+                        </input>
+                        <input pattern="^closed$">
+                            <match>
+                                <action function="play-file" data="ivr/main-menu-closed.wav"/>
+                            </match>
+                            <nomatch>
+                                <action function="play-file" data="ivr/ivr-invalid_sound_prompt.wav"/>
+                            </nomatch>
+                        </input>
+                    </macro>
+                </macros>
+            </phrases>
+        </language>
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "languages")
+	form.Add("lang", "en")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	languages.Languages(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
+
+func TestLanguagesHandlerNotFound(t *testing.T) {
+	expect := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<document type="freeswitch/xml">
+    <section name="result">
+        <result status="not found" />
+    </section>
+</document>
+`
+
+	//create fake request
+	form := url.Values{} // Create fake form (as if it was posted)
+	form.Add("hostname", "fs-01")
+	form.Add("section", "languages")
+	form.Add("lang", "fr")
+	r, _ := http.NewRequest("POST", "http://nowhere.local", strings.NewReader(form.Encode()))
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	languages.Languages(w, r)
+	if w.Body.String() != expect {
+		t.Errorf("\n\nExpected:\n%s\n\nGot:\n%s\n", expect, w.Body.String())
+	}
+}
@@ -0,0 +1,182 @@
+package moduledata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/romana/rlog"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// formats module data can be written in, looked up in this order
+	extensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+	// decoded module data by file. Every file is checked for changes on its own when it is read, so
+	// editing one host file only reloads that file
+	cache   = map[string]cachedFile{}
+	cacheMu sync.Mutex
+)
+
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	data    []byte
+}
+
+// Find returns the file holding the module data of `path`. The extension of `path` is ignored, the
+// data can be in any of the supported formats but only in one of them
+func Find(path string) (string, error) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	found := []string{}
+	for _, ext := range extensions {
+		if _, err := os.Stat(base + ext); err == nil {
+			found = append(found, base+ext)
+		}
+	}
+	switch len(found) {
+	case 0:
+		// let the caller fail on the configured name
+		return path, nil
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("module data in more than one format [%s]", strings.Join(found, ", "))
+}
+
+// ReadFile reads module data in any supported format and returns it as json, so every format decodes
+// into the same structs through their json tags. The file is only decoded again once it changed
+func ReadFile(path string) ([]byte, error) {
+	f, err := Find(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(f)
+	if err != nil {
+		// the file was removed
+		forget(f)
+		return nil, err
+	}
+	cacheMu.Lock()
+	c, ok := cache[f]
+	cacheMu.Unlock()
+	if ok && c.modTime.Equal(fi.ModTime()) && c.size == fi.Size() {
+		return c.data, nil
+	}
+	d, err := decode(f)
+	if err != nil {
+		return nil, err
+	}
+	rlog.Debugf("loaded module data [%s]", f)
+	// the data it was converted from in another format is not needed anymore
+	forget(f)
+	cacheMu.Lock()
+	cache[f] = cachedFile{modTime: fi.ModTime(), size: fi.Size(), data: d}
+	cacheMu.Unlock()
+	return d, nil
+}
+
+// forget drops the module data of a file from the cache in every format, for changes written within
+// the resolution of its mtime and for files that were removed or converted to another format
+func forget(f string) {
+	base := strings.TrimSuffix(f, filepath.Ext(f))
+	cacheMu.Lock()
+	for _, ext := range extensions {
+		delete(cache, base+ext)
+	}
+	cacheMu.Unlock()
+}
+
+// knownExtension reports if `f` is module data in one of the supported formats
+func knownExtension(f string) bool {
+	for _, ext := range extensions {
+		if filepath.Ext(f) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func decode(f string) ([]byte, error) {
+	d, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	switch filepath.Ext(f) {
+	case ".yaml", ".yml":
+		if err = yaml.Unmarshal(d, &v); err != nil {
+			return nil, fmt.Errorf("could not decode yaml [%s] [%s]", f, err.Error())
+		}
+	case ".toml":
+		m := map[string]interface{}{}
+		if err = toml.Unmarshal(d, &m); err != nil {
+			return nil, fmt.Errorf("could not decode toml [%s] [%s]", f, err.Error())
+		}
+		v = m
+	default:
+		return d, nil
+	}
+	v, err = stringKeys(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s [%s]", err.Error(), f)
+	}
+	return json.Marshal(v)
+}
+
+// stringKeys converts the maps yaml decodes with non string keys, like a mapping of rates, to the
+// string keyed maps json needs
+func stringKeys(v interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, e := range t {
+			c, err := stringKeys(e)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = c
+		}
+		return m, nil
+	case map[string]interface{}:
+		for k, e := range t {
+			c, err := stringKeys(e)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = c
+		}
+		return t, nil
+	case []interface{}:
+		for i, e := range t {
+			c, err := stringKeys(e)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = c
+		}
+		return t, nil
+	case []map[string]interface{}:
+		for _, e := range t {
+			if _, err := stringKeys(e); err != nil {
+				return nil, err
+			}
+		}
+		return t, nil
+	case float64:
+		// json can not encode these, they only come from a yaml .inf or .nan
+		if math.IsInf(t, 0) || math.IsNaN(t) {
+			return nil, errors.New("infinite or nan value in module data")
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,144 @@
+package moduledata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/romana/rlog"
+)
+
+const (
+	// directory next to the shared module data files holding one directory per host
+	hostsDirectory = "hosts"
+)
+
+var (
+	// entries that can have their own host file, group entries like `group:us` stay in the shared file.
+	// Checked before a hostname from a request is used in a path
+	hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// HostFile returns the file holding the module data `path` for a single host,
+// hosts/<hostname>/<module> next to the shared file, in any of the supported formats
+func HostFile(path string, hostname string) (string, bool, error) {
+	if !hostnamePattern.MatchString(hostname) {
+		return "", false, nil
+	}
+	f, err := Find(hostPath(path, hostname))
+	if err != nil {
+		return "", false, err
+	}
+	if _, err = os.Stat(f); err != nil {
+		// a removed host file is dropped from the cache
+		forget(f)
+		return "", false, nil
+	}
+	return f, true, nil
+}
+
+func hostPath(path string, hostname string) string {
+	return filepath.Join(filepath.Dir(path), hostsDirectory, hostname, filepath.Base(path))
+}
+
+// Read returns the module data `path` as needed to answer a lookup for `hostname`: the entries of
+// the shared file with the entry of the host taken from its host file when it has one. Only the file
+// of the requesting host is read, a shared file is not needed once every host has its own
+func Read(path string, hostname string) ([]byte, error) {
+	f, ok, err := HostFile(path, hostname)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return ReadFile(path)
+	}
+	h, err := readShared(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok = h[hostname]; ok {
+		rlog.Warnf("hostname in shared module data and host file, using the host file [%s] [%s]", path, f)
+	}
+	if h[hostname], err = ReadFile(f); err != nil {
+		return nil, err
+	}
+	return json.Marshal(h)
+}
+
+// ReadAll returns the entries of the shared file together with those of every host file
+func ReadAll(path string) (map[string]json.RawMessage, error) {
+	h, err := readShared(path)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := ioutil.ReadDir(filepath.Join(filepath.Dir(path), hostsDirectory))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, d := range dirs {
+		f, ok, err := HostFile(path, d.Name())
+		if err != nil {
+			return nil, err
+		}
+		if !d.IsDir() || !ok {
+			continue
+		}
+		if h[d.Name()], err = ReadFile(f); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// WriteEntry replaces a single entry of the module data `path`. An entry that has a host file is
+// written there, any other to the shared file
+func WriteEntry(path string, entry string, d json.RawMessage) error {
+	f, ok, err := HostFile(path, entry)
+	if err != nil {
+		return err
+	}
+	if ok {
+		b, err := json.MarshalIndent(d, "", "\t")
+		if err != nil {
+			return err
+		}
+		return WriteFile(f, append(b, '\n'))
+	}
+	h := map[string]json.RawMessage{}
+	b, err := ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, &h); err != nil {
+		return err
+	}
+	h[entry] = d
+	if b, err = json.MarshalIndent(h, "", "\t"); err != nil {
+		return err
+	}
+	return WriteFile(path, append(b, '\n'))
+}
+
+// readShared returns the entries of the shared file, none when there is no shared file
+func readShared(path string) (map[string]json.RawMessage, error) {
+	h := map[string]json.RawMessage{}
+	f, err := Find(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = os.Stat(f); os.IsNotExist(err) {
+		forget(f)
+		return h, nil
+	}
+	d, err := ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(d, &h); err != nil {
+		return nil, fmt.Errorf("could not unmarshal [%s] [%s]", f, err.Error())
+	}
+	return h, nil
+}
@@ -0,0 +1,45 @@
+package moduledata
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/romana/rlog"
+)
+
+// WriteFile replaces a module data file through a rename so FreeSWITCH lookups never read a
+// partially written file. The permissions of the file being replaced are kept. Only json module data
+// is written, rewriting yaml or toml would drop the comments people keep in them
+func WriteFile(path string, d []byte) error {
+	current, err := Find(path)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(current) != ".json" {
+		return fmt.Errorf("module data is in [%s], changes can only be written to json", current)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		rlog.Errorf("could not create temp file [%s]", err.Error())
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(d); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if fi, err := os.Stat(path); err == nil {
+		os.Chmod(f.Name(), fi.Mode())
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		rlog.Errorf("could not replace file [%s]", err.Error())
+		return err
+	}
+	forget(path)
+	return nil
+}
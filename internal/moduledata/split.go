@@ -0,0 +1,128 @@
+package moduledata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/romana/rlog"
+)
+
+var (
+	// shared files not keyed by hostname. modules.json keeps the roles hosts load their modules from
+	// next to the hosts themselves
+	unsplit = map[string]bool{
+		"modules.json": true,
+	}
+)
+
+// Move is a host entry moved out of a shared module data file
+type Move struct {
+	From     string
+	To       string
+	Hostname string
+}
+
+// Split moves the host entries of the shared json module data files in `dir` to their own files
+// under hosts/<hostname>/. Entries that are not hostnames, like the groups hosts inherit from, stay
+// in the shared file, which is removed once nothing is left in it. Nothing is changed when a host
+// file already exists, a yaml or toml file has hostnames or `dryRun` is set
+func Split(dir string, dryRun bool) ([]Move, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	moves := []Move{}
+	shared := map[string]map[string]json.RawMessage{}
+	for _, fi := range files {
+		path := filepath.Join(dir, fi.Name())
+		if fi.IsDir() || unsplit[fi.Name()] {
+			continue
+		}
+		if !knownExtension(path) {
+			continue
+		}
+		h, err := readShared(path)
+		if err != nil {
+			return nil, err
+		}
+		names := []string{}
+		for n := range h {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		before := len(moves)
+		for _, n := range names {
+			if !hostnamePattern.MatchString(n) {
+				continue
+			}
+			f, ok, err := HostFile(path, n)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return nil, fmt.Errorf("host file already exists [%s]", f)
+			}
+			moves = append(moves, Move{From: path, To: hostPath(path, n), Hostname: n})
+		}
+		if len(moves) == before {
+			continue
+		}
+		// rewriting yaml or toml would drop the comments people keep in them
+		if filepath.Ext(path) != ".json" {
+			return nil, fmt.Errorf("hostnames in [%s], only json module data can be split, convert it to json first", path)
+		}
+		shared[path] = h
+	}
+	if dryRun {
+		return moves, nil
+	}
+	// host files are written before the shared files drop their entries, so every lookup in between
+	// finds the host
+	for _, m := range moves {
+		if err = writeHostFile(m, shared[m.From][m.Hostname]); err != nil {
+			return nil, err
+		}
+		delete(shared[m.From], m.Hostname)
+	}
+	for path, h := range shared {
+		if len(h) == 0 {
+			if err = os.Remove(path); err != nil {
+				return nil, err
+			}
+			rlog.Infof("removed empty shared module data [%s]", path)
+			continue
+		}
+		d, err := json.MarshalIndent(h, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		if err = WriteFile(path, append(d, '\n')); err != nil {
+			return nil, err
+		}
+	}
+	return moves, nil
+}
+
+// writeHostFile creates a host file with the permissions of the shared file it is split from
+func writeHostFile(m Move, d json.RawMessage) error {
+	fi, err := os.Stat(m.From)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(m.To), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(d, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err = WriteFile(m.To, append(b, '\n')); err != nil {
+		return err
+	}
+	rlog.Infof("moved hostname to its own module data [%s] [%s]", m.Hostname, m.To)
+	return os.Chmod(m.To, fi.Mode())
+}
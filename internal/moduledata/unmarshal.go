@@ -0,0 +1,111 @@
+package moduledata
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// Unmarshal decodes module data into v like json.Unmarshal, also accepting numbers and booleans
+// where v has a string. Hand written yaml and toml give `value: 5060` or `value: true` their native
+// type while FreeSWITCH params are strings
+func Unmarshal(d []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(d))
+	// keeps numbers as written, 1.50 stays 1.50 when it ends up in a string
+	dec.UseNumber()
+	var g interface{}
+	if err := dec.Decode(&g); err != nil {
+		return err
+	}
+	b, err := json.Marshal(toStrings(g, reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// toStrings converts the scalars of g that json would decode into a string of t
+func toStrings(g interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// json.RawMessage and other types decoding themselves get the value as written
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return g
+	}
+	switch t.Kind() {
+	case reflect.String:
+		switch s := g.(type) {
+		case json.Number:
+			return s.String()
+		case bool:
+			return strconv.FormatBool(s)
+		}
+	case reflect.Slice, reflect.Array:
+		if l, ok := g.([]interface{}); ok {
+			for i := range l {
+				l[i] = toStrings(l[i], t.Elem())
+			}
+		}
+	case reflect.Map:
+		if m, ok := g.(map[string]interface{}); ok {
+			for k := range m {
+				m[k] = toStrings(m[k], t.Elem())
+			}
+		}
+	case reflect.Struct:
+		if m, ok := g.(map[string]interface{}); ok {
+			fields := map[string]reflect.Type{}
+			jsonFields(t, fields)
+			for k := range m {
+				// json matches keys to fields case insensitively
+				if f, ok := fields[strings.ToLower(k)]; ok {
+					m[k] = toStrings(m[k], f)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// jsonFields collects the fields of a struct by their lowercased json name, with the fields of
+// embedded structs promoted like json does. Fields of the struct itself win over promoted ones
+func jsonFields(t reflect.Type, fields map[string]reflect.Type) {
+	embedded := []reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				embedded = append(embedded, et)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = f.Type
+		}
+	}
+	for _, et := range embedded {
+		jsonFields(et, fields)
+	}
+}
@@ -0,0 +1,65 @@
+package secret
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/romana/rlog"
+)
+
+const (
+	envPrefix  = "env:"
+	filePrefix = "file:"
+)
+
+var (
+	secretsDirectory string
+)
+
+// New sets the directory `file:` secret references are resolved against
+func New(d string) error {
+	secretsDirectory = d
+	rlog.Infof("set secrets directory [%s]", secretsDirectory)
+	return nil
+}
+
+// Get resolves a secret reference to its value. References are either `env:NAME`, read from the
+// environment, or `file:NAME`, read from NAME in the secrets directory with trailing newlines removed
+func Get(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, envPrefix):
+		name := strings.TrimPrefix(ref, envPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			rlog.Errorf("secret environment variable not set [%s]", name)
+			return "", errors.New("secret not found")
+		}
+		return v, nil
+	case strings.HasPrefix(ref, filePrefix):
+		name := strings.TrimPrefix(ref, filePrefix)
+		if secretsDirectory == "" {
+			rlog.Errorf("secrets directory not set for reference [%s]", ref)
+			return "", errors.New("secrets directory not set")
+		}
+		if name == "" || filepath.IsAbs(name) || strings.HasPrefix(filepath.Clean(name), "..") {
+			rlog.Errorf("invalid secret file reference [%s]", ref)
+			return "", errors.New("invalid secret reference")
+		}
+		d, err := ioutil.ReadFile(filepath.Join(secretsDirectory, name))
+		if err != nil {
+			rlog.Errorf("could not read secret file [%s]", err.Error())
+			return "", err
+		}
+		v := strings.TrimRight(string(d), "\r\n")
+		if v == "" {
+			rlog.Errorf("secret file is empty [%s]", name)
+			return "", errors.New("secret not found")
+		}
+		return v, nil
+	}
+	rlog.Errorf("unsupported secret reference [%s]", ref)
+	return "", errors.New("unsupported secret reference")
+}
@@ -8,7 +8,12 @@ import (
 	"github.com/romana/rlog"
 	"goji.io"
 
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/admin"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/cdr"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/modules/generic"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/freeswitch/sections/directory"
 	"github.com/voipxswitch/freeswitch-xml-configuration/internal/http"
+	"github.com/voipxswitch/freeswitch-xml-configuration/internal/secret"
 )
 
 var (
@@ -36,13 +41,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	// command line operations on module data
+	if flag.NArg() > 0 {
+		if err = runCommand(c, flag.Args()); err != nil {
+			rlog.Errorf("command failed [%s]", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// http settings
 	if c.HTTP.ListenHTTP != "" {
 		listenAddressHttp = c.HTTP.ListenHTTP
 	}
 
+	// secret references in module data
+	err = secret.New(c.FreeSWITCH.SecretsDirectory)
+	if err != nil {
+		rlog.Errorf("could not setup secrets [%s]", err.Error())
+		os.Exit(1)
+	}
+
+	// admin api
+	err = admin.New(c.Admin.Username, c.Admin.PasswordSecret)
+	if err != nil {
+		rlog.Errorf("could not setup admin api [%s]", err.Error())
+		os.Exit(1)
+	}
+
+	// directory settings
+	directory.AllowPlaintextPasswords(c.Directory.AllowPlaintextPasswords)
+
+	// cdr storage
+	err = cdr.Retention(c.CDR.RetentionDays)
+	if err != nil {
+		rlog.Errorf("could not setup cdr retention [%s]", err.Error())
+		os.Exit(1)
+	}
+	err = cdr.New(c.CDR.Directory, c.CDR.Username, c.CDR.PasswordSecret)
+	if err != nil {
+		rlog.Errorf("could not setup cdr storage [%s]", err.Error())
+		os.Exit(1)
+	}
+
 	// start http
-	err = http.New(goji.NewMux(), listenAddressHttp, c.FreeSWITCH.ModuleDataDirectory, c.HTTP.TemplatesDir)
+	err = http.New(goji.NewMux(), listenAddressHttp, c.FreeSWITCH.ModuleDataDirectory, c.HTTP.TemplatesDir, c.FreeSWITCH.GenericModules)
 	if err != nil {
 		rlog.Errorf("could not start http(s) server [%s]", err.Error())
 		os.Exit(1)
@@ -56,8 +99,23 @@ type serviceConfig struct {
 		ListenHTTP   string `json:"listen"`
 	} `json:"http"`
 	FreeSWITCH struct {
-		ModuleDataDirectory string `json:"module_data_directory"`
+		ModuleDataDirectory string         `json:"module_data_directory"`
+		SecretsDirectory    string         `json:"secrets_directory"`
+		GenericModules      []generic.Conf `json:"generic_modules"`
 	} `json:"freeswitch"`
+	Directory struct {
+		AllowPlaintextPasswords bool `json:"allow_plaintext_passwords"`
+	} `json:"directory"`
+	Admin struct {
+		Username       string `json:"username"`
+		PasswordSecret string `json:"password_secret"`
+	} `json:"admin"`
+	CDR struct {
+		Directory      string `json:"directory"`
+		Username       string `json:"username"`
+		PasswordSecret string `json:"password_secret"`
+		RetentionDays  int    `json:"retention_days"`
+	} `json:"cdr"`
 }
 
 func loadConfigFile(configFile string) (serviceConfig, error) {